@@ -0,0 +1,476 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/edit"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const applyOperation = "apply"
+
+var applyTemplate bool
+var applyDryRun bool
+var applyBranch string
+var applyMessage string
+var applyForce bool
+
+var defaultApplyColumns = []string{"status", "name", "path", "branch", "message"}
+var applyFieldNames = []string{"path", "branch"}
+
+// applyTemplateData is what a file rendered with --template can refer to.
+type applyTemplateData struct {
+	// Name of the repository directory
+	Name string
+	// Branch currently checked out
+	Branch string
+	// DefaultBranch of the origin remote without the remote name, e.g. main
+	DefaultBranch string
+	// Remote is the fetch URL of the origin remote, e.g. {{.Remote.Owner}}
+	Remote git.RemoteURL
+	Year   int
+}
+
+type appliedFile struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Diff   string `json:"diff,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	// Committed is set when the file was created or changed and committed
+	Committed bool `json:"committed"`
+}
+
+// Changes made to the file in each repository
+const (
+	fileCreated   = "created"
+	fileChanged   = "changed"
+	fileUnchanged = "unchanged"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <source> <dest-path>",
+	Short: "Copies a file into all sub-directories",
+	Long: `Copies a file into all sub-directories, e.g. to roll out a LICENSE, .editorconfig
+or CI workflow to every repository:
+
+  kl apply ~/templates/ci.yml .github/workflows/ci.yml
+
+The destination path is relative to the root of each repository. With --template
+the file is rendered as a Go template, which can refer to the Name, Branch,
+DefaultBranch and Remote of the repository and the current Year, e.g.
+'Copyright {{.Year}} {{.Remote.Owner}}'.
+
+Each repository is reported as created, changed or unchanged, with the changes
+shown as a diff. Repositories where the file has uncommitted changes are left
+unchanged, as are those where it exists but is not tracked by git, e.g. an
+ignored file, unless --force is given. With --message the file is committed, on
+a new branch when --branch is given, in repositories without other uncommitted
+changes.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		source, err := os.ReadFile(args[0])
+		cobra.CheckErr(err)
+
+		sourceInfo, err := os.Stat(args[0])
+		cobra.CheckErr(err)
+
+		dest, err := checkDestPath(args[1])
+		cobra.CheckErr(err)
+
+		var fileTemplate *template.Template
+		if applyTemplate {
+			fileTemplate, err = template.New(filepath.Base(args[0])).Option("missingkey=error").Parse(string(source))
+			cobra.CheckErr(err)
+		}
+
+		if applyBranch != "" && applyMessage == "" {
+			cobra.CheckErr("--branch needs a commit --message")
+		}
+
+		var messageTemplate *template.Template
+		if applyMessage != "" {
+			messageTemplate, err = parseCommitMessage(applyMessage)
+			cobra.CheckErr(err)
+		}
+
+		out := newOutputter(applyFieldNames, defaultApplyColumns...)
+
+		if applyBranch != "" {
+			cobra.CheckErr(git.Branch{Outputter: out}.CheckBranchName(applyBranch))
+		}
+
+		gitStatus := git.Status{
+			Outputter: out,
+		}
+
+		gitRemote := git.Remote{
+			Outputter: out,
+		}
+
+		gitDefaultBranch := git.DefaultBranch{
+			Outputter: out,
+			Manifest:  manifestDefaultBranches(),
+		}
+
+		gitLsFiles := git.LsFiles{
+			Outputter: out,
+		}
+
+		summary := output.NewSummary()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, applyOperation)
+				continue
+			}
+
+			status, err := gitStatus.Exec(repositoryDir)
+			if err != nil {
+				reportError(out, summary, repositoryName, applyOperation, "Unable to get status", err)
+				continue
+			}
+
+			untracked, err := untrackedFileExists(gitLsFiles, repositoryDir, dest)
+			if err != nil {
+				reportError(out, summary, repositoryName, applyOperation, "Unable to list tracked files", err)
+				continue
+			}
+
+			if message, ok := applyBlocked(status, dest, untracked); !ok {
+				report(out, summary, output.Result{
+					Repository: repositoryName,
+					Operation:  applyOperation,
+					Outcome:    output.Warning,
+					Message:    message,
+					Fields:     applyFields(dest, ""),
+				})
+				continue
+			}
+
+			content := string(source)
+
+			if fileTemplate != nil {
+				data := applyTemplateData{
+					Name:   repositoryName,
+					Branch: status.LocalBranch,
+					Year:   time.Now().Year(),
+				}
+
+				if remote, err := gitRemote.Exec(repositoryDir); err == nil {
+					data.Remote = remote.FetchURL
+				}
+
+				if detected, err := gitDefaultBranch.Exec(repositoryDir); err == nil {
+					data.DefaultBranch = withoutRemote(string(detected.Name))
+				}
+
+				if content, err = renderFile(fileTemplate, data); err != nil {
+					reportError(out, summary, repositoryName, applyOperation, "Unable to render template", err)
+					continue
+				}
+			}
+
+			applied, mode, err := compareFile(repositoryDir, dest, content, sourceInfo.Mode().Perm())
+			if err != nil {
+				reportError(out, summary, repositoryName, applyOperation, "Unable to read file", err)
+				continue
+			}
+
+			if applied.Change == fileUnchanged {
+				report(out, summary, applyResult(repositoryName, applied, output.Skipped, "Unchanged"))
+				continue
+			}
+
+			applied.Branch = applyBranch
+
+			if applyDryRun {
+				report(out, summary, applyResult(repositoryName, applied, output.OK, "Would be "+applied.Change))
+				continue
+			}
+
+			// The branch the file is committed to
+			branch := status.LocalBranch
+			if applyBranch != "" {
+				branch = applyBranch
+			}
+
+			// Rendered before any changes are made, so a failure leaves the repository as it was
+			var commitMessage string
+			if messageTemplate != nil {
+				if commitMessage, err = renderCommitMessage(messageTemplate, commitTemplateData{Name: repositoryName, Branch: branch}); err != nil {
+					reportError(out, summary, repositoryName, applyOperation, "Unable to create commit message", err)
+					continue
+				}
+			}
+
+			if applyBranch != "" {
+				if err := switchToNewBranch(out, repositoryDir, git.LocalBranchName(applyBranch)); err != nil {
+					reportError(out, summary, repositoryName, applyOperation, "Unable to create branch", err)
+					continue
+				}
+			}
+
+			if err := writeFile(repositoryDir, dest, content, mode); err != nil {
+				message := "Unable to write file"
+				if applyBranch != "" {
+					message += ", on branch " + applyBranch
+				}
+
+				reportError(out, summary, repositoryName, applyOperation, message, err)
+				continue
+			}
+
+			message := "Created"
+			if applied.Change == fileChanged {
+				message = "Changed"
+			}
+
+			if messageTemplate != nil {
+				if err := commitFiles(out, repositoryDir, commitMessage, []string{filepath.ToSlash(dest)}); err != nil {
+					report(out, summary, applyResult(repositoryName, applied, output.Failure, uncommittedMessage(branch, err)))
+					continue
+				}
+
+				applied.Committed = true
+				message = "Committed"
+			}
+
+			report(out, summary, applyResult(repositoryName, applied, output.OK, message))
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// checkDestPath returns the cleaned destination path, which must be within the
+// repository and outside of its .git directory.
+func checkDestPath(dest string) (string, error) {
+	clean := filepath.Clean(dest)
+
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid destination '%s', expected a path within each repository", dest)
+	}
+
+	if first := strings.Split(filepath.ToSlash(clean), "/")[0]; first == ".git" {
+		return "", fmt.Errorf("invalid destination '%s', expected a path outside of the .git directory", dest)
+	}
+
+	return clean, nil
+}
+
+// untrackedFileExists reports whether the destination exists in the repository
+// without being tracked by git, so it could not be recovered once replaced.
+func untrackedFileExists(gitLsFiles git.LsFiles, repositoryDir, dest string) (bool, error) {
+	if _, err := os.Lstat(filepath.Join(repositoryDir, dest)); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	tracked, err := gitLsFiles.ExecIsTracked(repositoryDir, filepath.ToSlash(dest))
+	return !tracked, err
+}
+
+// applyBlocked returns why the file cannot be applied to the repository, if it
+// cannot: changes to the file would be lost, or other changes would be committed.
+// A file that exists but is not tracked is only replaced with --force.
+func applyBlocked(status git.RepositoryStatus, dest string, untracked bool) (string, bool) {
+	if applyMessage != "" && (status.Staged > 0 || status.Unstaged > 0) {
+		return "Left unchanged: uncommitted changes", false
+	}
+
+	for _, f := range status.FilesStatus {
+		if (f.Path == filepath.ToSlash(dest) || f.OriginalPath == filepath.ToSlash(dest)) && (f.Staged || f.Unstaged) {
+			return "Left unchanged: uncommitted changes", false
+		}
+	}
+
+	if untracked && !applyForce {
+		return "Left unchanged: file exists but is not tracked, use --force to replace it", false
+	}
+
+	return "", true
+}
+
+func renderFile(t *template.Template, data applyTemplateData) (string, error) {
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// compareFile returns how the file in the repository would change and the mode
+// to write it with, keeping the mode of an existing file.
+func compareFile(repositoryDir, dest, content string, sourceMode os.FileMode) (appliedFile, os.FileMode, error) {
+	applied := appliedFile{
+		Path: filepath.ToSlash(dest),
+	}
+
+	info, err := destFileInfo(repositoryDir, dest)
+	if err != nil {
+		return applied, 0, err
+	}
+
+	if info == nil {
+		applied.Change = fileCreated
+		return applied, sourceMode, nil
+	}
+
+	existing, err := os.ReadFile(filepath.Join(repositoryDir, dest))
+	if err != nil {
+		return applied, 0, err
+	}
+
+	if string(existing) == content {
+		applied.Change = fileUnchanged
+		return applied, info.Mode().Perm(), nil
+	}
+
+	applied.Change = fileChanged
+
+	if !edit.IsBinary(existing) && !edit.IsBinary([]byte(content)) {
+		applied.Diff = edit.UnifiedDiff(applied.Path, string(existing), content)
+	}
+
+	return applied, info.Mode().Perm(), nil
+}
+
+// destFileInfo returns the destination file, or nil when it does not exist. It
+// must be a regular file, not a symlink, and its directory must not resolve to
+// outside of the repository, so only files within the repository are written.
+func destFileInfo(repositoryDir, dest string) (os.FileInfo, error) {
+	root, err := filepath.EvalSymlinks(repositoryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath := filepath.Join(repositoryDir, dest)
+
+	// Missing directories are created when the file is written, so the nearest
+	// one that exists is checked
+	for dir := filepath.Dir(filePath); ; dir = filepath.Dir(dir) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rel, err := filepath.Rel(root, resolved)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("'%s' is outside of the repository", filepath.ToSlash(dest))
+		}
+
+		break
+	}
+
+	info, err := os.Lstat(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("'%s' is a directory", filepath.ToSlash(dest))
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("'%s' is not a regular file", filepath.ToSlash(dest))
+	}
+
+	return info, nil
+}
+
+func writeFile(repositoryDir, dest, content string, mode os.FileMode) error {
+	// Checked again as the branch may have been switched since the file was read
+	if _, err := destFileInfo(repositoryDir, dest); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(repositoryDir, dest)
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, []byte(content), mode)
+}
+
+func applyResult(repositoryName string, applied appliedFile, outcome output.Outcome, message string) output.Result {
+	result := output.Result{
+		Repository: repositoryName,
+		Operation:  applyOperation,
+		Outcome:    outcome,
+		Message:    message,
+		Data:       applied,
+		Fields:     applyFields(applied.Path, applied.Branch),
+	}
+
+	if applied.Diff != "" {
+		result.Details = diffDetails(applied.Diff)
+	}
+
+	return result
+}
+
+func applyFields(path, branch string) []output.Field {
+	return []output.Field{
+		{Name: "path", Value: filepath.ToSlash(path)},
+		{Name: "branch", Value: branch},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.PersistentFlags().BoolVar(&applyTemplate, "template", false, "render the file as a Go template with the details of each repository")
+	applyCmd.PersistentFlags().BoolVarP(&applyDryRun, "dry-run", "d", false, "show what would be done, without making any changes.")
+	applyCmd.PersistentFlags().StringVarP(&applyBranch, "branch", "b", "", "commit the file on a new branch")
+	applyCmd.PersistentFlags().BoolVar(&applyForce, "force", false, "replace files that exist but are not tracked by git, e.g. ignored files")
+	applyCmd.PersistentFlags().StringVarP(&applyMessage, "message", "m", "", "commit the file with the message, which can refer to the {{.Name}} of the repository")
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDestPath(t *testing.T) {
+	tests := []struct {
+		dest  string
+		clean string
+		valid bool
+	}{
+		{".editorconfig", ".editorconfig", true},
+		{".github/workflows/ci.yml", ".github/workflows/ci.yml", true},
+		{"./docs/../LICENSE", "LICENSE", true},
+		{".gitignore", ".gitignore", true},
+		{"..", "", false},
+		{"../other/file", "", false},
+		{"docs/../../file", "", false},
+		{".", "", false},
+		{"/etc/passwd", "", false},
+		{".git/config", "", false},
+		{"./.git/hooks/pre-commit", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			// When
+			clean, err := checkDestPath(tt.dest)
+
+			// Then
+			assert.Equal(t, err == nil, tt.valid)
+			assert.Equal(t, clean, filepath.FromSlash(tt.clean))
+		})
+	}
+}
+
+func TestApplyBlocked(t *testing.T) {
+	clean := git.RepositoryStatus{}
+
+	otherChange := git.RepositoryStatus{
+		Unstaged:    1,
+		FilesStatus: []git.FileStatus{{Path: "README.md", Unstaged: true}},
+	}
+
+	tests := []struct {
+		name      string
+		status    git.RepositoryStatus
+		dest      string
+		untracked bool
+		force     bool
+		message   string
+		reason    string
+	}{
+		{"clean", clean, "ci.yml", false, false, "", ""},
+		{"other file changed", otherChange, "ci.yml", false, false, "", ""},
+		{"other file changed with commit", otherChange, "ci.yml", false, false, "Add CI", "Left unchanged: uncommitted changes"},
+		{"file changed", otherChange, "README.md", false, false, "", "Left unchanged: uncommitted changes"},
+		{"file with space changed", git.RepositoryStatus{
+			Unstaged:    1,
+			FilesStatus: []git.FileStatus{{Path: "my file.txt", Unstaged: true}},
+		}, "my file.txt", false, false, "", "Left unchanged: uncommitted changes"},
+		{"file in directory staged", git.RepositoryStatus{
+			Staged:      1,
+			FilesStatus: []git.FileStatus{{Path: "docs/guide.md", Staged: true}},
+		}, filepath.FromSlash("docs/guide.md"), false, false, "", "Left unchanged: uncommitted changes"},
+		{"file renamed", git.RepositoryStatus{
+			Staged:      1,
+			FilesStatus: []git.FileStatus{{Path: "new.md", OriginalPath: "old.md", Staged: true}},
+		}, "old.md", false, false, "", "Left unchanged: uncommitted changes"},
+		{"untracked file", clean, ".editorconfig", true, false, "", "Left unchanged: file exists but is not tracked, use --force to replace it"},
+		{"untracked file with force", clean, ".editorconfig", true, true, "", ""},
+		{"changed file with force", otherChange, "README.md", false, true, "", "Left unchanged: uncommitted changes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			applyForce, applyMessage = tt.force, tt.message
+			defer func() { applyForce, applyMessage = false, "" }()
+
+			// When
+			reason, ok := applyBlocked(tt.status, tt.dest, tt.untracked)
+
+			// Then
+			assert.Equal(t, reason, tt.reason)
+			assert.Equal(t, ok, tt.reason == "")
+		})
+	}
+}
+
+func TestCompareFileCreated(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+
+	// When
+	applied, mode, err := compareFile(dir, filepath.Join("docs", "new.md"), "content\n", 0755)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, applied, appliedFile{Path: "docs/new.md", Change: fileCreated})
+	assert.Equal(t, mode, os.FileMode(0755))
+}
+
+func TestCompareFileUnchanged(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("content\n"), 0600))
+
+	// When
+	applied, mode, err := compareFile(dir, "file.txt", "content\n", 0644)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, applied, appliedFile{Path: "file.txt", Change: fileUnchanged})
+	assert.Equal(t, mode, os.FileMode(0600))
+}
+
+func TestCompareFileChanged(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one\ntwo\n"), 0644))
+
+	// When
+	applied, _, err := compareFile(dir, "file.txt", "one\nthree\n", 0644)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, applied.Change, fileChanged)
+	assert.Contains(t, applied.Diff, "-two\n")
+	assert.Contains(t, applied.Diff, "+three\n")
+}
+
+func TestCompareFileChangedBinary(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "image.png"), []byte("\x89PNG\x00"), 0644))
+
+	// When
+	applied, _, err := compareFile(dir, "image.png", "\x89PNG\x00\x01", 0644)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, applied.Change, fileChanged)
+	assert.Equal(t, applied.Diff, "")
+}
+
+func TestCompareFileDirectory(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "docs"), 0755))
+
+	// When
+	_, _, err := compareFile(dir, "docs", "content\n", 0644)
+
+	// Then
+	assert.EqualError(t, err, "'docs' is a directory")
+}
+
+func TestCompareFileSymlink(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "target.txt")
+	assert.NoError(t, os.WriteFile(outside, []byte("outside\n"), 0644))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(dir, "file.txt")))
+
+	// When
+	_, _, err := compareFile(dir, "file.txt", "content\n", 0644)
+
+	// Then
+	assert.EqualError(t, err, "'file.txt' is not a regular file")
+}
+
+func TestCompareFileSymlinkedDirectory(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	shared := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(shared, "ci.yml"), []byte("shared\n"), 0644))
+	assert.NoError(t, os.Symlink(shared, filepath.Join(dir, ".github")))
+
+	// When
+	_, _, err := compareFile(dir, filepath.Join(".github", "ci.yml"), "content\n", 0644)
+
+	// Then
+	assert.EqualError(t, err, "'.github/ci.yml' is outside of the repository")
+}
+
+func TestCompareFileSymlinkedDirectoryCreated(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	assert.NoError(t, os.Symlink(t.TempDir(), filepath.Join(dir, ".github")))
+
+	// When
+	_, _, err := compareFile(dir, filepath.Join(".github", "workflows", "ci.yml"), "content\n", 0644)
+
+	// Then
+	assert.EqualError(t, err, "'.github/workflows/ci.yml' is outside of the repository")
+}
+
+func TestCompareFileSymlinkedDirectoryInRepository(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "docs"), 0755))
+	assert.NoError(t, os.Symlink("docs", filepath.Join(dir, "documentation")))
+
+	// When
+	applied, _, err := compareFile(dir, filepath.Join("documentation", "guide.md"), "content\n", 0644)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, applied.Change, fileCreated)
+}
+
+func TestWriteFileSymlink(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "target.txt")
+	assert.NoError(t, os.WriteFile(outside, []byte("outside\n"), 0644))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(dir, "file.txt")))
+
+	// When
+	err := writeFile(dir, "file.txt", "content\n", 0644)
+
+	// Then
+	assert.EqualError(t, err, "'file.txt' is not a regular file")
+	content, _ := os.ReadFile(outside)
+	assert.Equal(t, string(content), "outside\n")
+}
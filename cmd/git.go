@@ -16,10 +16,14 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // gitCmd represents the git command
@@ -53,3 +57,75 @@ func isGitRepository(dir string) bool {
 
 	return err == nil
 }
+
+// manifestDefaultBranches returns the default branch of each repository given
+// in the repositories section of the config file, e.g.
+//
+//	repositories:
+//	  kl-cli:
+//	    default-branch: develop
+func manifestDefaultBranches() map[string]string {
+	branches := map[string]string{}
+
+	// Names are lower case, as viper reads keys case insensitively
+	for name := range viper.GetStringMap("repositories") {
+		if branch := viper.GetString("repositories." + name + ".default-branch"); branch != "" {
+			branches[name] = branch
+		}
+	}
+
+	return branches
+}
+
+// report outputs the result and adds it to the summary.
+func report(out output.Outputter, summary *output.Summary, result output.Result) {
+	summary.Add(result)
+	out.Result(result)
+}
+
+// reportNotVersioned outputs that the directory was skipped as it is not a git repository.
+func reportNotVersioned(out output.Outputter, summary *output.Summary, repositoryName, operation string) {
+	summary.CountNotVersioned()
+	out.Result(output.Result{
+		Repository: repositoryName,
+		Operation:  operation,
+		Outcome:    output.Skipped,
+		Message:    git.NotVersioned.Message,
+	})
+}
+
+// reportError outputs that the operation failed for the repository.
+func reportError(out output.Outputter, summary *output.Summary, repositoryName, operation, message string, err error) {
+	report(out, summary, output.Result{
+		Repository: repositoryName,
+		Operation:  operation,
+		Outcome:    output.Failure,
+		Message:    message + ": " + err.Error(),
+	})
+}
+
+// switchToNewBranch creates the branch at HEAD and checks it out, so that
+// changes are committed to it rather than the current branch.
+func switchToNewBranch(out output.Outputter, repositoryDir string, branch git.LocalBranchName) error {
+	if err := (git.Branch{Outputter: out}).ExecCreate(repositoryDir, branch, "HEAD"); err != nil {
+		return err
+	}
+
+	return git.Checkout{Outputter: out}.Exec(repositoryDir, branch)
+}
+
+// uncommittedMessage describes a repository whose changes were written but not
+// committed, e.g. when a hook rejected the commit, with the branch they are on.
+func uncommittedMessage(branch string, err error) string {
+	return fmt.Sprintf("Unable to commit, changes left uncommitted on branch %s: %s", branch, err.Error())
+}
+
+// commitFiles stages the files, given relative to the repository, and commits
+// them with the message.
+func commitFiles(out output.Outputter, repositoryDir, message string, files []string) error {
+	if err := (git.Add{Outputter: out}).Exec(repositoryDir, files); err != nil {
+		return err
+	}
+
+	return git.Commit{Outputter: out}.Exec(repositoryDir, message, false)
+}
@@ -0,0 +1,294 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var branchRemote bool
+var branchDryRun bool
+var branchRepositories []string
+var branchFrom string
+var branchForce bool
+
+var branchFieldNames = []string{"branch", "from", "new-branch"}
+
+// branchAction applies a branch operation to a single repository.
+type branchAction func(out output.Outputter, repositoryDir string) output.Result
+
+var branchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "Creates, renames and deletes branches across all sub-directories",
+	Long: `Creates, renames and deletes branches across all sub-directories, or those
+given with --repositories.
+
+Repositories where the operation cannot be done, e.g. because the branch
+already exists, are skipped with the reason. With --remote the branch is also
+created, renamed or deleted on origin.`,
+}
+
+var branchCreateCmd = &cobra.Command{
+	Use:   "create <branch>",
+	Short: "Creates a branch in all sub-directories",
+	Long: `Creates a branch in all sub-directories, starting from the ref given with
+--from or, by default, the default branch of each repository.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		branch := git.LocalBranchName(args[0])
+
+		runBranchOperation("branch create", branch, []git.LocalBranchName{branch}, func(out output.Outputter, repositoryDir string) output.Result {
+			gitBranch := git.Branch{Outputter: out}
+
+			if gitBranch.ExecExists(repositoryDir, "refs/heads/"+string(branch)) {
+				return branchSkipped("Branch already exists")
+			}
+
+			from := branchFrom
+			if from == "" {
+				detected, err := git.DefaultBranch{Outputter: out, Manifest: manifestDefaultBranches()}.Exec(repositoryDir)
+				if err != nil {
+					return branchSkipped("Unable to detect default branch")
+				}
+				from = string(detected.Name)
+			}
+
+			if !gitBranch.ExecExists(repositoryDir, from) {
+				return branchSkipped(fmt.Sprintf("Source ref %s not found", from))
+			}
+
+			if branchDryRun {
+				return withFrom(branchSkipped("Dry Run: branch will be created"), from)
+			}
+
+			if err := gitBranch.ExecCreate(repositoryDir, branch, from); err != nil {
+				return branchFailed("Unable to create branch", err)
+			}
+
+			if branchRemote {
+				if err := (git.Push{Outputter: out}).ExecSetUpstream(repositoryDir, branch); err != nil {
+					return withFrom(branchFailed("Branch created, unable to push it", err), from)
+				}
+			}
+
+			return withFrom(branchDone("Branch created"), from)
+		})
+	},
+}
+
+var branchRenameCmd = &cobra.Command{
+	Use:   "rename <branch> <new-branch>",
+	Short: "Renames a branch in all sub-directories",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		branch := git.LocalBranchName(args[0])
+		newBranch := git.LocalBranchName(args[1])
+
+		runBranchOperation("branch rename", branch, []git.LocalBranchName{newBranch}, func(out output.Outputter, repositoryDir string) output.Result {
+			gitBranch := git.Branch{Outputter: out}
+
+			if !gitBranch.ExecExists(repositoryDir, "refs/heads/"+string(branch)) {
+				return branchSkipped("Branch not found")
+			}
+
+			if gitBranch.ExecExists(repositoryDir, "refs/heads/"+string(newBranch)) {
+				return branchSkipped("New branch already exists")
+			}
+
+			onRemote := gitBranch.ExecExists(repositoryDir, "refs/remotes/origin/"+string(branch))
+
+			if branchDryRun {
+				return withNewBranch(branchSkipped("Dry Run: branch will be renamed"), newBranch)
+			}
+
+			if err := gitBranch.ExecRename(repositoryDir, branch, newBranch); err != nil {
+				return branchFailed("Unable to rename branch", err)
+			}
+
+			if branchRemote && onRemote {
+				gitPush := git.Push{Outputter: out}
+
+				if err := gitPush.ExecSetUpstream(repositoryDir, newBranch); err != nil {
+					return withNewBranch(branchFailed("Branch renamed, unable to push it", err), newBranch)
+				}
+
+				if err := gitPush.ExecDelete(repositoryDir, branch); err != nil {
+					return withNewBranch(branchFailed("Branch renamed, unable to delete the old remote branch", err), newBranch)
+				}
+			}
+
+			return withNewBranch(branchDone("Branch renamed"), newBranch)
+		})
+	},
+}
+
+var branchDeleteCmd = &cobra.Command{
+	Use:   "delete <branch>",
+	Short: "Deletes a branch in all sub-directories",
+	Long: `Deletes a branch in all sub-directories. Branches that have not been merged
+are only deleted with --force.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		branch := git.LocalBranchName(args[0])
+
+		runBranchOperation("branch delete", branch, nil, func(out output.Outputter, repositoryDir string) output.Result {
+			gitBranch := git.Branch{Outputter: out}
+
+			local := gitBranch.ExecExists(repositoryDir, "refs/heads/"+string(branch))
+			onRemote := branchRemote && gitBranch.ExecExists(repositoryDir, "refs/remotes/origin/"+string(branch))
+
+			if !local && !onRemote {
+				return branchSkipped("Branch not found")
+			}
+
+			if local {
+				current, err := gitBranch.ExecCurrent(repositoryDir)
+				if err != nil {
+					return branchFailed("Unable to read current branch", err)
+				}
+
+				if current == branch {
+					return branchSkipped("Branch is checked out")
+				}
+			}
+
+			if branchDryRun {
+				return branchSkipped("Dry Run: branch will be deleted")
+			}
+
+			if local {
+				deleteBranch := gitBranch.ExecDeleteMerged
+				if branchForce {
+					deleteBranch = gitBranch.ExecDelete
+				}
+
+				if err := deleteBranch(repositoryDir, branch); err != nil {
+					return branchFailed("Unable to delete branch, use --force if it is not merged", err)
+				}
+			}
+
+			if onRemote {
+				if err := (git.Push{Outputter: out}).ExecDelete(repositoryDir, branch); err != nil {
+					return branchFailed("Unable to delete remote branch", err)
+				}
+			}
+
+			return branchDone("Branch deleted")
+		})
+	},
+}
+
+// runBranchOperation validates the names of any new branches then applies the
+// action to each selected repository.
+func runBranchOperation(operation string, branch git.LocalBranchName, newBranches []git.LocalBranchName, action branchAction) {
+	out := newOutputter(branchFieldNames)
+
+	for _, newBranch := range newBranches {
+		cobra.CheckErr(git.Branch{Outputter: out}.CheckBranchName(string(newBranch)))
+	}
+
+	summary := output.NewSummary()
+
+	// Find directories
+	entries, err := os.ReadDir(WorkingDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Loop through directories
+	for _, entry := range entries {
+		if !entry.IsDir() || !branchRepositorySelected(entry.Name()) {
+			continue
+		}
+
+		repositoryName := entry.Name()
+
+		repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+		if !isGitRepository(repositoryDir) {
+			reportNotVersioned(out, summary, repositoryName, operation)
+			continue
+		}
+
+		result := action(out, repositoryDir)
+		result.Repository = repositoryName
+		result.Operation = operation
+		result.Fields = append([]output.Field{{Name: "branch", Value: string(branch)}}, result.Fields...)
+
+		report(out, summary, result)
+	}
+
+	out.Summary(summary.Finish())
+	cobra.CheckErr(out.Close())
+}
+
+func branchRepositorySelected(repositoryName string) bool {
+	if len(branchRepositories) == 0 {
+		return true
+	}
+
+	for _, name := range branchRepositories {
+		if strings.EqualFold(name, repositoryName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func branchDone(message string) output.Result {
+	return output.Result{Outcome: output.OK, Message: message}
+}
+
+func branchSkipped(reason string) output.Result {
+	return output.Result{Outcome: output.Skipped, Message: reason}
+}
+
+func branchFailed(message string, err error) output.Result {
+	return output.Result{Outcome: output.Failure, Message: message + ": " + err.Error()}
+}
+
+func withFrom(result output.Result, from string) output.Result {
+	result.Fields = append(result.Fields, output.Field{Name: "from", Value: from})
+	return result
+}
+
+func withNewBranch(result output.Result, newBranch git.LocalBranchName) output.Result {
+	result.Fields = append(result.Fields, output.Field{Name: "new-branch", Value: string(newBranch)})
+	return result
+}
+
+func init() {
+	gitCmd.AddCommand(branchCmd)
+	branchCmd.AddCommand(branchCreateCmd)
+	branchCmd.AddCommand(branchRenameCmd)
+	branchCmd.AddCommand(branchDeleteCmd)
+
+	branchCmd.PersistentFlags().BoolVar(&branchRemote, "remote", false, "also create, rename or delete the branch on origin")
+	branchCmd.PersistentFlags().BoolVarP(&branchDryRun, "dry-run", "d", false, "show what would be done, without making any changes.")
+	branchCmd.PersistentFlags().StringSliceVar(&branchRepositories, "repositories", nil, "only change these repositories")
+
+	branchCreateCmd.Flags().StringVar(&branchFrom, "from", "", "ref to create the branch from (default is the default branch)")
+	branchDeleteCmd.Flags().BoolVar(&branchForce, "force", false, "delete the branch even if it has not been merged")
+}
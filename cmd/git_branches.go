@@ -0,0 +1,365 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const branchesOperation = "branches"
+
+const (
+	localBranchType  = "local"
+	remoteBranchType = "remote"
+)
+
+var pivotBranches bool
+
+var defaultBranchesColumns = []string{"status", "name", "branch", "type", "age", "merged", "message"}
+var defaultPivotColumns = []string{"branch", "count", "repositories"}
+var branchesFieldNames = []string{"branch", "type", "upstream", "age", "author", "merged"}
+var pivotFieldNames = []string{"branch", "count", "repositories"}
+
+// branchPivot is the repositories that have a branch of the same name, either
+// locally or on a remote.
+type branchPivot struct {
+	Branch       string   `json:"branch"`
+	Repositories []string `json:"repositories"`
+}
+
+var branchesCmd = &cobra.Command{
+	Use:   "branches [pattern]",
+	Short: "Lists the local and remote branches of all sub-directories",
+	Long: `Lists the local and remote branches of all sub-directories.
+
+Only branches containing the pattern are listed, e.g. 'kl git branches ABC-123',
+or matching it when it has wildcards, e.g. 'kl git branches "feature/*"'.
+
+Each branch is shown with the state of its upstream, the age of its last commit
+and whether it has been merged into the default branch. With --pivot the
+repositories that have each branch are listed instead.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		var pattern string
+		if len(args) > 0 {
+			pattern = args[0]
+		}
+
+		// Validate the pattern before doing any work
+		_, err := matchBranch(pattern, "", false)
+		cobra.CheckErr(err)
+
+		fields, columns := branchesFieldNames, defaultBranchesColumns
+		if pivotBranches {
+			fields, columns = pivotFieldNames, defaultPivotColumns
+		}
+
+		out := newOutputter(fields, columns...)
+
+		gitBranch := git.Branch{
+			Outputter: out,
+		}
+
+		gitDefaultBranch := git.DefaultBranch{
+			Outputter: out,
+			Manifest:  manifestDefaultBranches(),
+		}
+
+		summary := output.NewSummary()
+		pivots := map[string]*branchPivot{}
+		now := time.Now()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				if pivotBranches {
+					summary.CountNotVersioned()
+				} else {
+					reportNotVersioned(out, summary, repositoryName, branchesOperation)
+				}
+				continue
+			}
+
+			results, err := branchResults(repositoryDir, repositoryName, pattern, gitBranch, gitDefaultBranch, now)
+			if err != nil {
+				reportError(out, summary, repositoryName, branchesOperation, "Unable to list branches", err)
+				continue
+			}
+
+			for _, result := range results {
+				if pivotBranches {
+					addPivot(pivots, repositoryName, result)
+					continue
+				}
+
+				if branchType, _ := result.Field("type"); branchType == remoteBranchType {
+					summary.Count("Remote branches")
+				} else {
+					summary.Count("Local branches")
+				}
+
+				out.Result(result)
+			}
+		}
+
+		if pivotBranches {
+			for _, result := range pivotResults(pivots) {
+				summary.Count("Branches")
+				out.Result(result)
+			}
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// branchResults lists the local and remote branches of a repository that match
+// the pattern.
+func branchResults(repositoryDir, repositoryName, pattern string, gitBranch git.Branch, gitDefaultBranch git.DefaultBranch,
+	now time.Time) ([]output.Result, error) {
+
+	localBranches, err := gitBranch.ExecLocal(repositoryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteBranches, err := gitBranch.ExecRemoteBranches(repositoryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merged is left empty when there is no default branch to compare with
+	var defaultBranch string
+	var merged map[string]bool
+
+	if detected, err := gitDefaultBranch.Exec(repositoryDir); err == nil {
+		defaultBranch = string(detected.Name)
+
+		if merged, err = gitBranch.ExecMerged(repositoryDir, defaultBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	mergedCell := func(name string) string {
+		switch {
+		case merged == nil:
+			return ""
+		case name == defaultBranch || "origin/"+name == defaultBranch:
+			return "default"
+		case merged[name]:
+			return "yes"
+		}
+		return "no"
+	}
+
+	var results []output.Result
+
+	for _, lb := range localBranches {
+		name := string(lb.LocalBranchName)
+
+		if ok, _ := matchBranch(pattern, name, false); !ok {
+			continue
+		}
+
+		result := output.Result{
+			Repository: repositoryName,
+			Operation:  branchesOperation,
+			Outcome:    output.OK,
+			Message:    trackMessage(lb),
+			Data:       lb,
+			Fields: []output.Field{
+				{Name: "branch", Value: name},
+				{Name: "type", Value: localBranchType},
+				{Name: "upstream", Value: string(lb.RemoteBranchName)},
+				{Name: "age", Value: output.Age(lb.LastCommitDate, now)},
+				{Name: "author", Value: lb.LastCommitAuthor},
+				{Name: "merged", Value: mergedCell(name)},
+			},
+		}
+
+		if lb.Track.Gone {
+			result.Outcome = output.Warning
+		}
+
+		results = append(results, result)
+	}
+
+	for _, rb := range remoteBranches {
+		name := string(rb.RemoteBranchName)
+
+		if ok, _ := matchBranch(pattern, name, true); !ok {
+			continue
+		}
+
+		results = append(results, output.Result{
+			Repository: repositoryName,
+			Operation:  branchesOperation,
+			Outcome:    output.OK,
+			Message:    "Remote branch",
+			Data:       rb,
+			Fields: []output.Field{
+				{Name: "branch", Value: name},
+				{Name: "type", Value: remoteBranchType},
+				{Name: "upstream", Value: ""},
+				{Name: "age", Value: output.Age(rb.LastCommitDate, now)},
+				{Name: "author", Value: rb.LastCommitAuthor},
+				{Name: "merged", Value: mergedCell(name)},
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// matchBranch reports whether the branch contains the pattern, ignoring case,
+// or matches it when it has wildcards. Remote branches are matched without the
+// name of the remote, so a pattern such as 'origin' does not match all of
+// them, though wildcards can also match the full name, e.g. 'origin/*'.
+func matchBranch(pattern, branch string, remote bool) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+
+	names := []string{branch}
+	if remote {
+		names = []string{withoutRemote(branch), branch}
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.Contains(strings.ToLower(names[0]), strings.ToLower(pattern)), nil
+	}
+
+	for _, name := range names {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func withoutRemote(branch string) string {
+	if i := strings.Index(branch, "/"); i != -1 {
+		return branch[i+1:]
+	}
+	return branch
+}
+
+func trackMessage(lb git.LocalBranch) string {
+	switch {
+	case lb.RemoteBranchName == "":
+		return "No upstream"
+	case lb.Track.Gone:
+		return "Upstream gone"
+	case lb.Track.Ahead > 0 && lb.Track.Behind > 0:
+		return fmt.Sprintf("Ahead %d, behind %d", lb.Track.Ahead, lb.Track.Behind)
+	case lb.Track.Ahead > 0:
+		return fmt.Sprintf("Ahead %d", lb.Track.Ahead)
+	case lb.Track.Behind > 0:
+		return fmt.Sprintf("Behind %d", lb.Track.Behind)
+	}
+	return "Up to date"
+}
+
+// addPivot adds the repository to the branch, matching local branches with
+// remote branches of the same name.
+func addPivot(pivots map[string]*branchPivot, repositoryName string, result output.Result) {
+	name, _ := result.Field("branch")
+
+	if branchType, _ := result.Field("type"); branchType == remoteBranchType {
+		name = withoutRemote(name)
+	}
+
+	pivot, ok := pivots[name]
+	if !ok {
+		pivot = &branchPivot{Branch: name}
+		pivots[name] = pivot
+	}
+
+	for _, r := range pivot.Repositories {
+		if r == repositoryName {
+			return
+		}
+	}
+
+	pivot.Repositories = append(pivot.Repositories, repositoryName)
+}
+
+func pivotResults(pivots map[string]*branchPivot) []output.Result {
+	var names []string
+	for name := range pivots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []output.Result
+
+	for _, name := range names {
+		pivot := pivots[name]
+
+		results = append(results, output.Result{
+			Operation: branchesOperation,
+			Outcome:   output.OK,
+			Data:      pivot,
+			Fields: []output.Field{
+				{Name: "branch", Value: pivot.Branch},
+				{Name: "count", Value: strconv.Itoa(len(pivot.Repositories))},
+				{Name: "repositories", Value: strings.Join(pivot.Repositories, ", ")},
+			},
+		})
+	}
+
+	return results
+}
+
+func init() {
+	gitCmd.AddCommand(branchesCmd)
+
+	branchesCmd.PersistentFlags().BoolVar(&pivotBranches, "pivot", false, "list the repositories that have each branch")
+}
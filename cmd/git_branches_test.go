@@ -0,0 +1,90 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchBranch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		branch  string
+		remote  bool
+		matched bool
+	}{
+		{"", "main", false, true},
+		{"abc", "feature/ABC-123", false, true},
+		{"abc", "main", false, false},
+		{"origin", "origin/main", true, false},
+		{"main", "origin/main", true, true},
+		{"feature/*", "feature/ABC-123", false, true},
+		{"feature/*", "origin/feature/ABC-123", true, true},
+		{"origin/*", "origin/main", true, true},
+		{"ABC*", "feature/ABC-123", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.branch, func(t *testing.T) {
+			// When
+			matched, err := matchBranch(tt.pattern, tt.branch, tt.remote)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, matched, tt.matched)
+		})
+	}
+}
+
+func TestMatchBranchInvalidPattern(t *testing.T) {
+	// When
+	_, err := matchBranch("[", "main", false)
+
+	// Then
+	assert.NotNil(t, err)
+}
+
+func TestPivotResults(t *testing.T) {
+	// Given
+	pivots := map[string]*branchPivot{}
+	branch := func(name, branchType string) output.Result {
+		return output.Result{Fields: []output.Field{{Name: "branch", Value: name}, {Name: "type", Value: branchType}}}
+	}
+
+	// When
+	addPivot(pivots, "repo-a", branch("main", localBranchType))
+	addPivot(pivots, "repo-a", branch("origin/main", remoteBranchType))
+	addPivot(pivots, "repo-b", branch("origin/main", remoteBranchType))
+	addPivot(pivots, "repo-b", branch("feature/x", localBranchType))
+
+	results := pivotResults(pivots)
+
+	// Then
+	assert.Equal(t, len(results), 2)
+
+	name, _ := results[0].Field("branch")
+	assert.Equal(t, name, "feature/x")
+
+	name, _ = results[1].Field("branch")
+	count, _ := results[1].Field("count")
+	repositories, _ := results[1].Field("repositories")
+	assert.Equal(t, name, "main")
+	assert.Equal(t, count, "2")
+	assert.Equal(t, repositories, "repo-a, repo-b")
+}
@@ -0,0 +1,258 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+const commitOperation = "commit"
+
+var commitMessage string
+var commitAll bool
+var commitBranch string
+var commitDryRun bool
+
+var defaultCommitColumns = []string{"status", "name", "branch", "files", "message"}
+var commitFieldNames = []string{"branch", "files"}
+
+// commitTemplateData is what a commit message template can refer to.
+type commitTemplateData struct {
+	// Name of the repository directory
+	Name string
+	// Branch the changes are committed to
+	Branch string
+}
+
+type repositoryCommitted struct {
+	Branch  string `json:"branch"`
+	Message string `json:"message"`
+	Files   int    `json:"files"`
+}
+
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Runs 'git commit' across all sub-directories with uncommitted changes",
+	Long: `Runs 'git commit' across all sub-directories with uncommitted changes, using the
+same message for each repository.
+
+Only staged changes are committed, or all changes to tracked files with --all.
+Repositories without changes to commit are skipped. With --branch the changes are
+committed to a new branch.
+
+The message is a Go template that can refer to the Name of the repository and the
+Branch committed to, e.g.
+
+  kl git commit --all -m "Upgrade logging in {{.Name}}"`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if commitMessage == "" {
+			cobra.CheckErr("a commit --message is required")
+		}
+
+		messageTemplate, err := parseCommitMessage(commitMessage)
+		cobra.CheckErr(err)
+
+		out := newOutputter(commitFieldNames, defaultCommitColumns...)
+
+		if commitBranch != "" {
+			cobra.CheckErr(git.Branch{Outputter: out}.CheckBranchName(commitBranch))
+		}
+
+		gitStatus := git.Status{
+			Outputter: out,
+		}
+
+		gitCommit := git.Commit{
+			Outputter: out,
+		}
+
+		summary := output.NewSummary()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, commitOperation)
+				continue
+			}
+
+			status, err := gitStatus.Exec(repositoryDir)
+			if err != nil {
+				reportError(out, summary, repositoryName, commitOperation, "Unable to get status", err)
+				continue
+			}
+
+			files, message := filesToCommit(status, commitAll)
+			if message != "" {
+				report(out, summary, output.Result{
+					Repository: repositoryName,
+					Operation:  commitOperation,
+					Outcome:    output.Skipped,
+					Message:    message,
+					Fields:     commitFields(status.LocalBranch, 0),
+				})
+				continue
+			}
+
+			committed := repositoryCommitted{
+				Branch: status.LocalBranch,
+				Files:  files,
+			}
+
+			if commitBranch != "" {
+				committed.Branch = commitBranch
+			}
+
+			committed.Message, err = renderCommitMessage(messageTemplate, commitTemplateData{
+				Name:   repositoryName,
+				Branch: committed.Branch,
+			})
+			if err != nil {
+				reportError(out, summary, repositoryName, commitOperation, "Unable to create commit message", err)
+				continue
+			}
+
+			result := output.Result{
+				Repository: repositoryName,
+				Operation:  commitOperation,
+				Outcome:    output.OK,
+				Message:    "Committed",
+				Data:       committed,
+				Details:    []string{committed.Message},
+				Fields:     commitFields(committed.Branch, files),
+			}
+
+			if commitDryRun {
+				result.Message = "Would commit"
+				report(out, summary, result)
+				continue
+			}
+
+			if commitBranch != "" {
+				if err := switchToNewBranch(out, repositoryDir, git.LocalBranchName(commitBranch)); err != nil {
+					reportError(out, summary, repositoryName, commitOperation, "Unable to create branch", err)
+					continue
+				}
+			}
+
+			if err := gitCommit.Exec(repositoryDir, committed.Message, commitAll); err != nil {
+				reportError(out, summary, repositoryName, commitOperation, "Unable to commit", err)
+				continue
+			}
+
+			report(out, summary, result)
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// parseCommitMessage parses the message template, checking it can be rendered.
+func parseCommitMessage(message string) (*template.Template, error) {
+	t, err := template.New("message").Option("missingkey=error").Parse(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit message: %w", err)
+	}
+
+	if _, err := renderCommitMessage(t, commitTemplateData{}); err != nil {
+		return nil, fmt.Errorf("invalid commit message: %w", err)
+	}
+
+	return t, nil
+}
+
+func renderCommitMessage(t *template.Template, data commitTemplateData) (string, error) {
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// filesToCommit returns the number of files that would be committed, or why the
+// repository is skipped when there are none.
+func filesToCommit(status git.RepositoryStatus, all bool) (int, string) {
+	files := status.Staged
+	if all {
+		files = countCommitted(status.FilesStatus)
+	}
+
+	switch {
+	case status.LocalStatus != git.UncommittedChanges:
+		return 0, "Nothing to commit"
+	case files == 0:
+		return 0, "Nothing staged"
+	}
+
+	return files, ""
+}
+
+// countCommitted returns the number of files with changes that 'git commit --all'
+// would commit, which leaves out untracked files.
+func countCommitted(files []git.FileStatus) int {
+	count := 0
+
+	for _, f := range files {
+		if f.Staged || f.Unstaged {
+			count++
+		}
+	}
+
+	return count
+}
+
+func commitFields(branch string, files int) []output.Field {
+	return []output.Field{
+		{Name: "branch", Value: branch},
+		{Name: "files", Value: strconv.Itoa(files)},
+	}
+}
+
+func init() {
+	gitCmd.AddCommand(commitCmd)
+
+	commitCmd.PersistentFlags().StringVarP(&commitMessage, "message", "m", "", "commit message, which can refer to the {{.Name}} of the repository")
+	commitCmd.PersistentFlags().BoolVarP(&commitAll, "all", "a", false, "commit all changes to tracked files, not only those staged")
+	commitCmd.PersistentFlags().StringVarP(&commitBranch, "branch", "b", "", "commit the changes on a new branch")
+	commitCmd.PersistentFlags().BoolVarP(&commitDryRun, "dry-run", "d", false, "show what would be done, without making any changes.")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommitMessage(t *testing.T) {
+	// When
+	_, err := parseCommitMessage("Upgrade logging in {{.Name}} on {{.Branch}}")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestParseCommitMessageUnknownField(t *testing.T) {
+	// When
+	_, err := parseCommitMessage("Upgrade logging in {{.Repository}}")
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid commit message")
+	assert.Contains(t, err.Error(), "Repository")
+}
+
+func TestParseCommitMessageInvalidTemplate(t *testing.T) {
+	// When
+	_, err := parseCommitMessage("Upgrade logging in {{.Name")
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid commit message")
+}
+
+func TestRenderCommitMessage(t *testing.T) {
+	// Given
+	messageTemplate, err := parseCommitMessage("Upgrade logging in {{.Name}} on {{.Branch}}")
+	assert.NoError(t, err)
+
+	// When
+	message, err := renderCommitMessage(messageTemplate, commitTemplateData{Name: "repo-a", Branch: "feature/logging"})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, message, "Upgrade logging in repo-a on feature/logging")
+}
+
+func TestCountCommitted(t *testing.T) {
+	// Given
+	files := []git.FileStatus{
+		{Path: "staged.go", Staged: true},
+		{Path: "unstaged.go", Unstaged: true},
+		{Path: "both.go", Staged: true, Unstaged: true},
+		{Path: "untracked.go", Untracked: true},
+		{Path: "ignored.log", Ignored: true},
+	}
+
+	// When
+	count := countCommitted(files)
+
+	// Then
+	assert.Equal(t, count, 3)
+}
+
+func TestFilesToCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  git.RepositoryStatus
+		all     bool
+		files   int
+		message string
+	}{
+		{"clean", git.RepositoryStatus{LocalStatus: git.NoChanges}, false, 0, "Nothing to commit"},
+		{"clean with all", git.RepositoryStatus{LocalStatus: git.NoChanges}, true, 0, "Nothing to commit"},
+		{"staged", git.RepositoryStatus{
+			LocalStatus: git.UncommittedChanges,
+			Staged:      2,
+			FilesStatus: []git.FileStatus{{Path: "a.go", Staged: true}, {Path: "b.go", Staged: true}},
+		}, false, 2, ""},
+		{"nothing staged", git.RepositoryStatus{
+			LocalStatus: git.UncommittedChanges,
+			Unstaged:    1,
+			FilesStatus: []git.FileStatus{{Path: "a.go", Unstaged: true}},
+		}, false, 0, "Nothing staged"},
+		{"unstaged with all", git.RepositoryStatus{
+			LocalStatus: git.UncommittedChanges,
+			Unstaged:    1,
+			FilesStatus: []git.FileStatus{{Path: "a.go", Unstaged: true}},
+		}, true, 1, ""},
+		{"untracked only with all", git.RepositoryStatus{
+			LocalStatus: git.UncommittedChanges,
+			Untracked:   1,
+			FilesStatus: []git.FileStatus{{Path: "new.go", Untracked: true}},
+		}, true, 0, "Nothing staged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			files, message := filesToCommit(tt.status, tt.all)
+
+			// Then
+			assert.Equal(t, files, tt.files)
+			assert.Equal(t, message, tt.message)
+		})
+	}
+}
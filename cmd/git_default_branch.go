@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultBranchOperation = "default-branch"
+
+var setHead bool
+
+var defaultBranchFieldNames = []string{"source"}
+
+var defaultBranchCmd = &cobra.Command{
+	Use:   "default-branch",
+	Short: "Shows the default branch of all sub-directories",
+	Long: `Shows the default branch of all sub-directories.
+
+The default branch is read from refs/remotes/origin/HEAD. When that is not set
+the origin remote is asked for its HEAD, then the default-branch of the
+repository in the repositories section of the config file is used, e.g.
+
+  repositories:
+    kl-cli:
+      default-branch: develop
+
+and finally origin/main or origin/master, whichever exists.
+
+With --set-head, refs/remotes/origin/HEAD is set to the detected branch in
+repositories where it is missing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		out := newOutputter(defaultBranchFieldNames)
+
+		gitDefaultBranch := git.DefaultBranch{
+			Outputter:   out,
+			Manifest:    manifestDefaultBranches(),
+			QueryRemote: true,
+		}
+
+		summary := output.NewSummary()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, defaultBranchOperation)
+				continue
+			}
+
+			branch, err := gitDefaultBranch.Exec(repositoryDir)
+			if err != nil {
+				report(out, summary, output.Result{
+					Repository: repositoryName,
+					Operation:  defaultBranchOperation,
+					Outcome:    output.Warning,
+					Message:    "Unable to detect default branch",
+				})
+				continue
+			}
+
+			result := output.Result{
+				Repository: repositoryName,
+				Operation:  defaultBranchOperation,
+				Outcome:    output.OK,
+				Message:    string(branch.Name),
+				Data:       branch,
+				Fields:     []output.Field{{Name: "source", Value: string(branch.Source)}},
+			}
+
+			if setHead && branch.Source != git.FromOriginHead {
+				if err := gitDefaultBranch.ExecSetHead(repositoryDir, branch.Name); err != nil {
+					reportError(out, summary, repositoryName, defaultBranchOperation, "Unable to set origin/HEAD", err)
+					continue
+				}
+
+				result.Message = fmt.Sprintf("Set origin/HEAD to %s", branch.Name)
+			}
+
+			out.Result(result)
+			summary.Count(string(branch.Source))
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(defaultBranchCmd)
+
+	defaultBranchCmd.PersistentFlags().BoolVar(&setHead, "set-head", false, "set refs/remotes/origin/HEAD where it is missing")
+}
@@ -16,7 +16,6 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
 	"github.com/klyall/kl-cli/pkg/git"
 	"github.com/klyall/kl-cli/pkg/output"
 	"log"
@@ -26,21 +25,22 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const fetchOperation = "fetch"
+
 var fetchCmd = &cobra.Command{
 	Use:   "fetch",
 	Short: "Runs 'git fetch' across all sub-directories",
 	Long:  `Runs 'git fetch' across all sub-directories.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		out := output.SStdOut{
-			Out: os.Stdout,
-		}
+		out := newOutputter(nil)
 
 		gitFetch := git.Fetch{
-			Verbose:   Verbose,
 			Outputter: out,
 		}
 
+		summary := output.NewSummary()
+
 		// Find directories
 		entries, err := os.ReadDir(WorkingDir)
 		if err != nil {
@@ -54,28 +54,29 @@ var fetchCmd = &cobra.Command{
 			}
 
 			repositoryName := entry.Name()
-			var message string
 
 			repositoryDir := filepath.Join(WorkingDir, repositoryName)
 
-			if isGitRepository(repositoryDir) {
-
-				err := gitFetch.Exec(repositoryDir)
-
-				if err != nil {
-					message := fmt.Sprintf("%-50s Unable to fetch git repository: %s", repositoryName, err.Error())
-					out.Error(message)
-					continue
-				}
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, fetchOperation)
+				continue
+			}
 
-				message = out.RenderInfo("Fetch complete")
-			} else {
-				message = out.RenderError("Not versioned")
+			if err := gitFetch.Exec(repositoryDir); err != nil {
+				reportError(out, summary, repositoryName, fetchOperation, "Unable to fetch git repository", err)
+				continue
 			}
 
-			cliMessage := fmt.Sprintf("%-50s %s", repositoryName, message)
-			out.Success(cliMessage)
+			report(out, summary, output.Result{
+				Repository: repositoryName,
+				Operation:  fetchOperation,
+				Outcome:    output.OK,
+				Message:    "Fetch complete",
+			})
 		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
 	},
 }
 
@@ -0,0 +1,157 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+const grepOperation = "grep"
+
+var grepOptions git.GrepOptions
+
+var defaultGrepColumns = []string{"name", "path", "line", "message"}
+var grepFieldNames = []string{"path", "line"}
+
+// grepSearch is the outcome of searching a repository.
+type grepSearch struct {
+	versioned bool
+	matches   []git.GrepMatch
+	err       error
+}
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern> [<pathspec>...]",
+	Short: "Runs 'git grep' across all sub-directories",
+	Long: `Runs 'git grep' across all sub-directories, searching the tracked files of the
+repositories in parallel.
+
+Each matching line is listed with the repository and the path of the file within
+it. The files searched can be limited with pathspecs, e.g.
+
+  kl git grep -i "todo" -- "*.go" ":!vendor"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		grepOptions.Pattern = args[0]
+		grepOptions.Pathspecs = args[1:]
+
+		out := newOutputter(grepFieldNames, defaultGrepColumns...)
+
+		gitGrep := git.Grep{
+			Outputter: out,
+		}
+
+		summary := output.NewSummary()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var repositoryNames []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				repositoryNames = append(repositoryNames, entry.Name())
+			}
+		}
+
+		searches := searchRepositories(repositoryNames, gitGrep)
+
+		// Report in the order of the directories, whichever search finished first
+		for i, repositoryName := range repositoryNames {
+			search := searches[i]
+
+			if !search.versioned {
+				reportNotVersioned(out, summary, repositoryName, grepOperation)
+				continue
+			}
+
+			if search.err != nil {
+				reportError(out, summary, repositoryName, grepOperation, "Unable to search git repository", search.err)
+				continue
+			}
+
+			for _, match := range search.matches {
+				summary.Count("Matches")
+
+				out.Result(output.Result{
+					Repository: repositoryName,
+					Operation:  grepOperation,
+					Outcome:    output.OK,
+					Message:    match.Text,
+					Data:       match,
+					Fields: []output.Field{
+						{Name: "path", Value: filepath.ToSlash(filepath.Join(repositoryName, match.Path))},
+						{Name: "line", Value: strconv.Itoa(match.Line)},
+					},
+				})
+			}
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// searchRepositories runs 'git grep' in the repositories, as many at a time as
+// there are CPUs.
+func searchRepositories(repositoryNames []string, gitGrep git.Grep) []grepSearch {
+	searches := make([]grepSearch, len(repositoryNames))
+
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, runtime.NumCPU())
+
+	for i, repositoryName := range repositoryNames {
+		wg.Add(1)
+
+		go func(i int, repositoryDir string) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			if !isGitRepository(repositoryDir) {
+				return
+			}
+
+			matches, err := gitGrep.Exec(repositoryDir, grepOptions)
+			searches[i] = grepSearch{versioned: true, matches: matches, err: err}
+		}(i, filepath.Join(WorkingDir, repositoryName))
+	}
+
+	wg.Wait()
+
+	return searches
+}
+
+func init() {
+	gitCmd.AddCommand(grepCmd)
+
+	grepCmd.PersistentFlags().BoolVarP(&grepOptions.ExtendedRegexp, "extended-regexp", "E", false, "match the pattern as an extended regular expression")
+	grepCmd.PersistentFlags().BoolVarP(&grepOptions.FixedStrings, "fixed-strings", "F", false, "match the pattern literally rather than as a regular expression")
+	grepCmd.PersistentFlags().BoolVarP(&grepOptions.IgnoreCase, "ignore-case", "i", false, "ignore case when matching")
+}
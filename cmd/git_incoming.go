@@ -0,0 +1,191 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const incomingOperation = "incoming"
+const outgoingOperation = "outgoing"
+
+var defaultPreviewColumns = []string{"status", "name", "branch", "upstream", "commits", "message"}
+var previewFieldNames = []string{"branch", "upstream", "commits"}
+
+// commitPreview is the commits that would be pulled from, or pushed to, the
+// upstream of the current branch.
+type commitPreview struct {
+	Branch   string         `json:"branch"`
+	Upstream string         `json:"upstream"`
+	Commits  []git.LogEntry `json:"commits"`
+}
+
+var incomingCmd = &cobra.Command{
+	Use:   "incoming",
+	Short: "Lists the commits that 'git pull' would bring into all sub-directories",
+	Long: `Lists the commits that 'git pull' would bring into all sub-directories.
+
+The commits on the upstream of the current branch that are not on HEAD are
+listed with their authors and the number of files they change. Run 'kl git fetch'
+first to see the latest commits on the remote.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runCommitPreview(incomingOperation, git.RemoteChanges.Message,
+			func(status git.RepositoryStatus) (int, string) {
+				return status.CommitsBehind, "HEAD.." + status.RemoteBranch
+			})
+	},
+}
+
+var outgoingCmd = &cobra.Command{
+	Use:   "outgoing",
+	Short: "Lists the commits that 'git push' would send from all sub-directories",
+	Long: `Lists the commits that 'git push' would send from all sub-directories.
+
+The commits on HEAD that are not on the upstream of the current branch are
+listed with their authors and the number of files they change.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runCommitPreview(outgoingOperation, git.CommittedChanges.Message,
+			func(status git.RepositoryStatus) (int, string) {
+				return status.CommitsAhead, status.RemoteBranch + "..HEAD"
+			})
+	},
+}
+
+// commitRange returns the number of commits to preview, as counted by
+// 'git status', and the range of commits to list.
+type commitRange func(status git.RepositoryStatus) (int, string)
+
+// runCommitPreview lists the commits in the range of each repository. Commits
+// are only listed for repositories that 'git status' shows have some.
+func runCommitPreview(operation, message string, commitRange commitRange) {
+
+	out := newOutputter(previewFieldNames, defaultPreviewColumns...)
+
+	gitStatus := git.Status{
+		Outputter: out,
+	}
+
+	gitLog := git.Log{
+		Outputter: out,
+	}
+
+	summary := output.NewSummary()
+
+	// Find directories
+	entries, err := os.ReadDir(WorkingDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Loop through directories
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		repositoryName := entry.Name()
+
+		repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+		if !isGitRepository(repositoryDir) {
+			reportNotVersioned(out, summary, repositoryName, operation)
+			continue
+		}
+
+		status, err := gitStatus.Exec(repositoryDir)
+		if err != nil {
+			reportError(out, summary, repositoryName, operation, "Unable to get status", err)
+			continue
+		}
+
+		preview := commitPreview{
+			Branch:   status.LocalBranch,
+			Upstream: status.RemoteBranch,
+		}
+
+		result := output.Result{
+			Repository: repositoryName,
+			Operation:  operation,
+			Outcome:    output.OK,
+			Message:    git.NoChanges.Message,
+		}
+
+		count, revision := commitRange(status)
+
+		switch {
+		case status.RemoteBranch == "":
+			result.Outcome = output.Skipped
+			result.Message = "No upstream"
+		case status.UpstreamGone:
+			// The commits of a deleted upstream cannot be counted
+			result.Outcome = output.Skipped
+			result.Message = "Upstream gone"
+		case count > 0:
+			preview.Commits, err = gitLog.Exec(repositoryDir, git.LogFilter{Revision: revision, Files: true})
+			if err != nil {
+				reportError(out, summary, repositoryName, operation, "Unable to list commits", err)
+				continue
+			}
+
+			result.Outcome = output.Warning
+			result.Message = message
+			result.Details = commitDetails(preview.Commits)
+		}
+
+		result.Data = preview
+		result.Fields = []output.Field{
+			{Name: "branch", Value: preview.Branch},
+			{Name: "upstream", Value: preview.Upstream},
+			{Name: "commits", Value: strconv.Itoa(len(preview.Commits))},
+		}
+
+		report(out, summary, result)
+	}
+
+	out.Summary(summary.Finish())
+	cobra.CheckErr(out.Close())
+}
+
+// commitDetails describes each commit on a line, e.g.
+//
+//	2f1c5c0 Add log command (Kevin Lyall, 2 files)
+func commitDetails(commits []git.LogEntry) []string {
+	var details []string
+
+	for _, c := range commits {
+		files := "1 file"
+		if c.FilesChanged != 1 {
+			files = fmt.Sprintf("%d files", c.FilesChanged)
+		}
+
+		details = append(details, fmt.Sprintf("%s %s (%s, %s)", c.ShortSHA(), c.Subject, c.Author, files))
+	}
+
+	return details
+}
+
+func init() {
+	gitCmd.AddCommand(incomingCmd)
+	gitCmd.AddCommand(outgoingCmd)
+}
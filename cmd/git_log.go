@@ -0,0 +1,157 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+const logOperation = "log"
+
+var logFilter git.LogFilter
+
+var defaultLogColumns = []string{"date", "name", "sha", "author", "message"}
+var logFieldNames = []string{"date", "sha", "author"}
+
+// repositoryCommit is a commit along with the repository it was made in, so
+// the commits of all repositories can be merged into one timeline.
+type repositoryCommit struct {
+	repository string
+	commit     git.LogEntry
+}
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Lists the recent commits of all sub-directories as one timeline",
+	Long: `Lists the recent commits of all sub-directories as one timeline, newest first.
+
+Each commit is tagged with the name of its repository, e.g. to write standup notes:
+
+  kl git log --since yesterday --author "Kevin"
+
+By default the commits of the last week on the current branch of each repository
+are listed. Use --branch to list the commits of another branch instead, skipping
+repositories that do not have it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		out := newOutputter(logFieldNames, defaultLogColumns...)
+
+		gitLog := git.Log{
+			Outputter: out,
+		}
+
+		gitBranch := git.Branch{
+			Outputter: out,
+		}
+
+		summary := output.NewSummary()
+
+		var commits []repositoryCommit
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, logOperation)
+				continue
+			}
+
+			ref := "HEAD"
+			if logFilter.Revision != "" {
+				ref = logFilter.Revision
+			}
+
+			if !gitBranch.ExecExists(repositoryDir, ref) {
+				message := "No commits"
+				if logFilter.Revision != "" {
+					message = "Branch not found"
+				}
+
+				report(out, summary, output.Result{
+					Repository: repositoryName,
+					Operation:  logOperation,
+					Outcome:    output.Skipped,
+					Message:    message,
+				})
+				continue
+			}
+
+			repositoryCommits, err := gitLog.Exec(repositoryDir, logFilter)
+			if err != nil {
+				reportError(out, summary, repositoryName, logOperation, "Unable to list commits", err)
+				continue
+			}
+
+			for _, commit := range repositoryCommits {
+				commits = append(commits, repositoryCommit{repositoryName, commit})
+			}
+		}
+
+		// Newest first, keeping the order of each repository for commits made at the same time
+		sort.SliceStable(commits, func(i, j int) bool {
+			return commits[i].commit.Date.After(commits[j].commit.Date)
+		})
+
+		for _, c := range commits {
+			summary.Count("Commits")
+
+			out.Result(output.Result{
+				Repository: c.repository,
+				Operation:  logOperation,
+				Outcome:    output.OK,
+				Message:    c.commit.Subject,
+				Data:       c.commit,
+				Fields: []output.Field{
+					{Name: "date", Value: c.commit.Date.Local().Format("2006-01-02 15:04")},
+					{Name: "sha", Value: c.commit.ShortSHA()},
+					{Name: "author", Value: c.commit.Author},
+				},
+			})
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(logCmd)
+
+	logCmd.PersistentFlags().StringVar(&logFilter.Since, "since", "1 week ago", "list commits more recent than a date, e.g. 'yesterday' or '2021-11-01'")
+	logCmd.PersistentFlags().StringVar(&logFilter.Author, "author", "", "list commits by authors matching the pattern")
+	logCmd.PersistentFlags().StringVar(&logFilter.Grep, "grep", "", "list commits with messages matching the pattern, ignoring case")
+	logCmd.PersistentFlags().StringVar(&logFilter.Revision, "branch", "", "list the commits of the branch instead of the current branch")
+}
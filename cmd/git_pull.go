@@ -16,7 +16,6 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
 	"github.com/klyall/kl-cli/pkg/git"
 	"github.com/klyall/kl-cli/pkg/output"
 	"log"
@@ -26,26 +25,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const pullOperation = "pull"
+
 var pullCmd = &cobra.Command{
 	Use:   "pull",
 	Short: "Runs 'git pull' across all sub-directories",
 	Long:  `Runs 'git pull' across all sub-directories.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		out := output.SStdOut{
-			Out: os.Stdout,
-		}
+		out := newOutputter(nil)
 
 		gitPull := git.Pull{
-			Verbose:   Verbose,
 			Outputter: out,
 		}
 
 		gitStatus := git.Status{
-			Verbose:   Verbose,
 			Outputter: out,
 		}
 
+		summary := output.NewSummary()
+
 		// Find directories
 		entries, err := os.ReadDir(WorkingDir)
 		if err != nil {
@@ -59,45 +58,47 @@ var pullCmd = &cobra.Command{
 			}
 
 			repositoryName := entry.Name()
-			var message string
 
 			repositoryDir := filepath.Join(WorkingDir, repositoryName)
 
-			if isGitRepository(repositoryDir) {
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, pullOperation)
+				continue
+			}
 
-				repositoryStatus, err := gitStatus.Exec(repositoryDir)
+			repositoryStatus, err := gitStatus.Exec(repositoryDir)
+			if err != nil {
+				reportError(out, summary, repositoryName, pullOperation, "Unable to pull git repository", err)
+				continue
+			}
+
+			result := output.Result{
+				Repository: repositoryName,
+				Operation:  pullOperation,
+			}
 
-				if err != nil {
-					message := fmt.Sprintf("%-50s Unable to pull git repository: %s", repositoryName, err.Error())
-					out.Error(message)
+			switch {
+			case repositoryStatus.LocalStatus == git.UncommittedChanges:
+				result.Outcome = output.Warning
+				result.Message = "Uncommitted changes prevent pull being done"
+			case repositoryStatus.RemoteStatus == git.NoChanges:
+				result.Outcome = output.OK
+				result.Message = "No changes to pull"
+			default:
+				if err := gitPull.Exec(repositoryDir); err != nil {
+					reportError(out, summary, repositoryName, pullOperation, "Unable to pull git repository", err)
 					continue
 				}
 
-				switch {
-				case repositoryStatus.LocalStatus == git.NotVersioned:
-					message = out.RenderSuccess("Directory is not versioned")
-				case repositoryStatus.LocalStatus == git.UncommittedChanges:
-					message = out.RenderError("Uncommitted changes prevent pull being done")
-				case repositoryStatus.RemoteStatus == git.NoChanges:
-					message = out.RenderSuccess("No changes to pull")
-				default:
-					err := gitPull.Exec(repositoryDir)
-
-					if err != nil {
-						message := fmt.Sprintf("%-50s Unable to pull git repository: %s", repositoryName, err.Error())
-						out.Error(message)
-						continue
-					}
-
-					message = out.RenderInfo("Pull complete")
-				}
-			} else {
-				message = out.RenderError("Not versioned")
+				result.Outcome = output.OK
+				result.Message = "Pull complete"
 			}
 
-			cliMessage := fmt.Sprintf("%-50s %s", repositoryName, message)
-			out.Success(cliMessage)
+			report(out, summary, result)
 		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
 	},
 }
 
@@ -26,27 +26,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const purgeOperation = "purge"
+
 var dryRun bool
 
+var purgeFieldNames = []string{"branch"}
+
 var purgeCmd = &cobra.Command{
 	Use:   "purge",
 	Short: "Runs git purge across all sub-directories",
 	Long:  `Removes all local branches that no longer have a valid remote branch.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		out := output.SStdOut{
-			Out: os.Stdout,
-		}
+		out := newOutputter(purgeFieldNames)
 
-		gitFetch := git.Fetch{
-			Verbose:   Verbose,
+		gitPurge := git.Purge{
 			Outputter: out,
+			DryRun:    dryRun,
 		}
 
-		gitBranch := git.Branch{
-			Verbose:   Verbose,
-			Outputter: out,
-		}
+		summary := output.NewSummary()
 
 		// Find directories
 		entries, err := os.ReadDir(WorkingDir)
@@ -61,81 +60,73 @@ var purgeCmd = &cobra.Command{
 			}
 
 			repositoryName := entry.Name()
-			var message string
 
 			repositoryDir := filepath.Join(WorkingDir, repositoryName)
 
 			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, purgeOperation)
 				continue
 			}
 
-			err := gitFetch.ExecWithPurge(repositoryDir)
-			if err != nil {
-				message := fmt.Sprintf("%-50s Unable to fetch git repository: %s", repositoryName, err.Error())
-				out.Error(message)
-				continue
-			}
-
-			remoteBranches, err := gitBranch.ExecRemote(repositoryDir)
+			purge, err := gitPurge.Exec(repositoryDir)
 			if err != nil {
-				message := fmt.Sprintf("%-50s Unable to retieve remote branches for repository: %s", repositoryName, err.Error())
-				out.Error(message)
+				reportError(out, summary, repositoryName, purgeOperation, "Unable to purge git repository", err)
 				continue
 			}
 
-			localBranches, err := gitBranch.ExecVV(repositoryDir)
-			if err != nil {
-				message := fmt.Sprintf("%-50s Unable to retieve branches for repository: %s", repositoryName, err.Error())
-				out.Error(message)
-				continue
+			for _, pb := range purge.Branches {
+				report(out, summary, purgedBranchResult(repositoryName, pb))
 			}
 
-			for _, lb := range localBranches {
-				if lb.RemoteBranchName != "" && !contains(remoteBranches, lb.RemoteBranchName) {
-					if lb.CurrentBranch {
-						msg := fmt.Sprintf("Unable to delete current branch '%s'", lb.LocalBranchName)
-						out.Error(formatMessage(repositoryName, msg))
-					} else if dryRun {
-						msg := fmt.Sprintf("Dry Run: %s branch will be deleted", lb.LocalBranchName)
-						out.Warn(formatMessage(repositoryName, msg))
-					} else {
-						err := gitBranch.ExecDelete(repositoryDir, lb.LocalBranchName)
-						if err != nil {
-							msg := fmt.Sprintf("Unable to delete local branch '%s': %s", lb.LocalBranchName, err.Error())
-							message := formatMessage(repositoryName, msg)
-							out.Error(message)
-						} else {
-							msg := fmt.Sprintf("%s branch deleted", lb.LocalBranchName)
-							out.Success(formatMessage(repositoryName, msg))
-						}
-					}
-				}
+			result := output.Result{
+				Repository: repositoryName,
+				Operation:  purgeOperation,
+				Outcome:    output.OK,
+				Data:       purge,
 			}
 
-			if len(remoteBranches) == 0 {
-				message = out.RenderInfo("No remote")
-			} else if dryRun {
-				message = out.RenderInfo("Purge Dry Run")
-			} else {
-				message = out.RenderInfo("Purged")
+			switch {
+			case !purge.HasRemote:
+				result.Outcome = output.Skipped
+				result.Message = "No remote"
+			case dryRun:
+				result.Message = "Purge Dry Run"
+			default:
+				result.Message = "Purged"
 			}
 
-			out.Success(formatMessage(repositoryName, message))
+			report(out, summary, result)
 		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
 	},
 }
 
-func formatMessage(repositoryName, message string) string {
-	return fmt.Sprintf("%-50s %s", repositoryName, message)
-}
+func purgedBranchResult(repositoryName string, pb git.PurgedBranch) output.Result {
+	result := output.Result{
+		Repository: repositoryName,
+		Operation:  purgeOperation,
+		Data:       pb,
+		Fields:     []output.Field{{Name: "branch", Value: string(pb.LocalBranchName)}},
+	}
 
-func contains(r []git.RemoteBranchName, branch git.RemoteBranchName) bool {
-	for _, b := range r {
-		if b == branch {
-			return true
-		}
+	switch pb.Outcome {
+	case git.BranchIsCurrent:
+		result.Outcome = output.Warning
+		result.Message = "Unable to delete current branch"
+	case git.BranchWouldBeDeleted:
+		result.Outcome = output.Skipped
+		result.Message = "Dry Run: branch will be deleted"
+	case git.BranchDeleteFailed:
+		result.Outcome = output.Failure
+		result.Message = fmt.Sprintf("Unable to delete local branch: %s", pb.Err.Error())
+	default:
+		result.Outcome = output.OK
+		result.Message = "Branch deleted"
 	}
-	return false
+
+	return result
 }
 
 func init() {
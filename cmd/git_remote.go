@@ -17,31 +17,41 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/gookit/color"
 	"github.com/klyall/kl-cli/pkg/git"
 	"github.com/klyall/kl-cli/pkg/output"
 	"github.com/spf13/cobra"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
+const remoteOperation = "remote"
+
+const ungroupedRemotes = "Other"
+
+// The group is shown as a heading rather than a column by default
+var defaultRemoteColumns = []string{"status", "name", "message"}
+var remoteFieldNames = []string{"group"}
+
 var remoteCmd = &cobra.Command{
 	Use:   "remote",
 	Short: "Runs 'git remote' across all sub-directories",
-	Long:  `Runs 'git remote' across all sub-directories.`,
+	Long: `Runs 'git remote' across all sub-directories.
+
+Repositories are grouped by the host and owner of their fetch URL and the
+browser URL of each repository is shown.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		out := output.SStdOut{
-			Out: os.Stdout,
-		}
+		out := newOutputter(remoteFieldNames, defaultRemoteColumns...)
 
 		gitRemote := git.Remote{
-			Verbose:   Verbose,
 			Outputter: out,
 		}
 
-		error := color.FgRed.Render
+		summary := output.NewSummary()
+
+		var results []output.Result
 
 		// Find directories
 		entries, err := os.ReadDir(WorkingDir)
@@ -56,40 +66,101 @@ var remoteCmd = &cobra.Command{
 				continue
 			}
 
-			var message string
-
 			repositoryName := entry.Name()
 
 			repositoryDir := filepath.Join(WorkingDir, repositoryName)
 
-			if isGitRepository(repositoryDir) {
-
-				remote, err := gitRemote.Exec(repositoryDir)
-				if err != nil {
-					message := fmt.Sprintf("%-50s Unable to fetch git repository: %s", repositoryName, err.Error())
-					out.Error(message)
-					continue
-				}
+			if !isGitRepository(repositoryDir) {
+				summary.CountNotVersioned()
+				results = append(results, output.Result{
+					Repository: repositoryName,
+					Operation:  remoteOperation,
+					Outcome:    output.Skipped,
+					Message:    git.NotVersioned.Message,
+					Group:      ungroupedRemotes,
+				})
+				continue
+			}
 
-				switch {
-				case remote.Fetch == "":
-					message = out.RenderInfo("No remote")
-				case remote.Fetch != remote.Push:
-					message = out.RenderWarn("Remotes mismatch: %s (fetch) %S (push)", remote.Fetch, remote.Push)
-				default:
-					message = remote.Fetch
+			remote, err := gitRemote.Exec(repositoryDir)
+			if err != nil {
+				result := output.Result{
+					Repository: repositoryName,
+					Operation:  remoteOperation,
+					Outcome:    output.Failure,
+					Message:    "Unable to read git remote: " + err.Error(),
+					Group:      ungroupedRemotes,
 				}
+				summary.Add(result)
+				results = append(results, result)
+				continue
+			}
 
+			result := remoteResult(repositoryName, remote)
+			if group, _ := result.Field("group"); group != "" && result.Outcome == output.OK {
+				summary.Count(group)
 			} else {
-				message = error("Not versioned")
+				summary.Add(result)
 			}
 
-			cliMessage := fmt.Sprintf("%-50s %s", repositoryName, message)
-			out.Success(cliMessage)
+			results = append(results, result)
+		}
+
+		sortRemoteGroups(results)
+
+		for _, result := range results {
+			out.Result(result)
 		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
 	},
 }
 
+// sortRemoteGroups orders the results by group name, with the repositories that
+// could not be grouped last, keeping the order of the directories within each
+// group.
+func sortRemoteGroups(results []output.Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		gi, gj := results[i].Group, results[j].Group
+		return gi != ungroupedRemotes && (gj == ungroupedRemotes || gi < gj)
+	})
+}
+
+// remoteResult describes the remote of a repository, which is counted in the
+// summary by its group rather than its URL.
+func remoteResult(repositoryName string, remote git.RepositoryRemote) output.Result {
+	group := remote.FetchURL.Group()
+
+	result := output.Result{
+		Repository: repositoryName,
+		Operation:  remoteOperation,
+		Outcome:    output.OK,
+		Data:       remote,
+		Group:      group,
+		Fields:     []output.Field{{Name: "group", Value: group}},
+	}
+
+	if group == "" {
+		result.Group = ungroupedRemotes
+	}
+
+	switch {
+	case remote.Fetch == "":
+		result.Outcome = output.Warning
+		result.Message = "No remote"
+	case remote.Fetch != remote.Push:
+		result.Outcome = output.Warning
+		result.Message = fmt.Sprintf("Remotes mismatch: %s (fetch) %s (push)", remote.Fetch, remote.Push)
+	case remote.FetchURL.BrowserURL() != "":
+		result.Message = remote.FetchURL.BrowserURL()
+	default:
+		result.Message = remote.Fetch
+	}
+
+	return result
+}
+
 func init() {
 	gitCmd.AddCommand(remoteCmd)
 }
@@ -17,34 +17,64 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/gookit/color"
 	"github.com/klyall/kl-cli/pkg/git"
 	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/klyall/kl-cli/pkg/watch"
 	"github.com/spf13/cobra"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
+const statusOperation = "status"
+
 var strict bool
+var watchStatus bool
+var showFiles bool
+var showDiffStat bool
+var baseRef string
+
+var defaultStatusColumns = []string{"status", "name", "branch", "version", "base", "base-ahead", "base-behind", "message"}
+
+// statusFieldNames are the fields of a status result, which can be selected with --columns.
+var statusFieldNames = []string{"branch", "remote", "version", "ahead", "behind", "default-branch", "base", "base-ahead",
+	"base-behind", "staged", "unstaged", "untracked"}
 
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Runs 'git status' across all sub-directories",
-	Long:  `A longer description that spans multiple lines `,
+	Long: `Runs 'git status' across all sub-directories.
+
+With --watch the status is kept up to date, re-running 'git status' only for
+repositories whose working tree or .git directory has changed. It can be used
+with the table, text and json outputs.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		out := output.SStdOut{
-			Out: os.Stdout,
+		// Validate the columns before doing any work
+		cobra.CheckErr(output.ValidateColumns(columnNames, statusFieldNames))
+
+		if watchStatus {
+			watchGitStatus()
+			return
 		}
 
+		out := newOutputter(statusFieldNames, defaultStatusColumns...)
+
 		gitStatus := git.Status{
-			Verbose:   Verbose,
-			Outputter: out,
-			Strict:    strict,
+			Outputter:  out,
+			Strict:     strict,
+			DiffStat:   showDiffStat,
+			Divergence: true,
+			Base:       baseRef,
+			Manifest:   manifestDefaultBranches(),
 		}
 
-		fmt.Printf("%-7s %-50s %-30s %-30s %s\n", "STATUS", "REPOSITORY NAME", "BRANCH", "VERSION", "MESSAGE")
+		summary := output.NewSummary()
 
 		// Find directories
 		entries, err := os.ReadDir(WorkingDir)
@@ -63,18 +93,273 @@ var statusCmd = &cobra.Command{
 			repositoryDir := filepath.Join(WorkingDir, repositoryName)
 
 			repositoryStatus, err := ExecuteGitStatus(repositoryDir, gitStatus)
-			if err != nil {
-				message := fmt.Sprintf("%-50s Unable to read git repository: %s", repositoryName, err.Error())
-				out.Error(message)
-				continue
+
+			out.Result(statusResult(repositoryName, repositoryStatus, err))
+			countStatus(summary, repositoryStatus, err)
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// statusResult describes the status of a repository. Every result has all of
+// the status fields, so any of them can be selected as a column.
+func statusResult(repositoryName string, repositoryStatus git.RepositoryStatus, err error) output.Result {
+	result := output.Result{
+		Repository: repositoryName,
+		Operation:  statusOperation,
+		Fields:     statusResultFields(repositoryStatus),
+	}
+
+	switch {
+	case err != nil:
+		result.Outcome = output.Failure
+		result.Message = "Unable to read git repository: " + err.Error()
+		return result
+	case repositoryStatus.LocalStatus == git.NotVersioned:
+		result.Outcome = output.Skipped
+	case repositoryStatus.LocalStatus == git.NoChanges && repositoryStatus.RemoteStatus == git.NoChanges:
+		result.Outcome = output.OK
+	default:
+		result.Outcome = output.Warning
+	}
+
+	result.Message = createMessage(repositoryStatus)
+	result.Data = repositoryStatus
+
+	if showFiles || showDiffStat {
+		result.Details = fileDetails(repositoryStatus)
+	}
+
+	return result
+}
+
+// fileDetails lists the changed files of a repository grouped by whether they
+// are staged, unstaged or untracked, with the lines changed when known.
+func fileDetails(repositoryStatus git.RepositoryStatus) []string {
+	var staged, unstaged, untracked []string
+
+	for _, fs := range repositoryStatus.FilesStatus {
+		if fs.Staged {
+			staged = append(staged, fileDetail(fs.Text[0], fs.Path, fs.StagedDiff))
+		}
+		if fs.Unstaged {
+			unstaged = append(unstaged, fileDetail(fs.Text[1], fs.Path, fs.UnstagedDiff))
+		}
+		if fs.Untracked {
+			untracked = append(untracked, fileDetail('?', fs.Path, nil))
+		}
+	}
+
+	var details []string
+	details = appendFileGroup(details, "Staged:", output.SuccessColor, staged)
+	details = appendFileGroup(details, "Unstaged:", output.WarnColor, unstaged)
+	details = appendFileGroup(details, "Untracked:", output.ErrorColor, untracked)
+
+	return details
+}
+
+func fileDetail(code byte, path string, diff *git.DiffStat) string {
+	detail := fmt.Sprintf("%c %s", code, path)
+
+	switch {
+	case diff == nil:
+	case diff.Binary:
+		detail += output.DebugColor.Render(" (binary)")
+	default:
+		detail += fmt.Sprintf(" %s %s", output.SuccessColor.Render(fmt.Sprintf("+%d", diff.Added)),
+			output.ErrorColor.Render(fmt.Sprintf("-%d", diff.Removed)))
+	}
+
+	return detail
+}
+
+func appendFileGroup(details []string, title string, c color.Color, files []string) []string {
+	if len(files) == 0 {
+		return details
+	}
+
+	details = append(details, title)
+
+	for _, file := range files {
+		details = append(details, "  "+c.Render(file[:1])+file[1:])
+	}
+
+	return details
+}
+
+func statusResultFields(repositoryStatus git.RepositoryStatus) []output.Field {
+	values := []string{
+		repositoryStatus.LocalBranch,
+		repositoryStatus.RemoteBranch,
+		repositoryStatus.VersionNumber,
+		countCell(repositoryStatus, repositoryStatus.CommitsAhead),
+		countCell(repositoryStatus, repositoryStatus.CommitsBehind),
+		repositoryStatus.DefaultBranch,
+		repositoryStatus.BaseBranch,
+		baseCountCell(repositoryStatus, repositoryStatus.CommitsAheadBase),
+		baseCountCell(repositoryStatus, repositoryStatus.CommitsBehindBase),
+		countCell(repositoryStatus, repositoryStatus.Staged),
+		countCell(repositoryStatus, repositoryStatus.Unstaged),
+		countCell(repositoryStatus, repositoryStatus.Untracked),
+	}
+
+	var fields []output.Field
+	for i, name := range statusFieldNames {
+		fields = append(fields, output.Field{Name: name, Value: values[i]})
+	}
+
+	return fields
+}
+
+func countCell(repositoryStatus git.RepositoryStatus, count int) string {
+	if !repositoryStatus.Versioned {
+		return ""
+	}
+	return fmt.Sprint(count)
+}
+
+func baseCountCell(repositoryStatus git.RepositoryStatus, count int) string {
+	if repositoryStatus.BaseBranch == "" {
+		return ""
+	}
+	return fmt.Sprint(count)
+}
+
+type watchedStatus struct {
+	status git.RepositoryStatus
+	err    error
+}
+
+// watchGitStatus redraws the status of every sub-directory each time one of
+// them changes, until interrupted.
+func watchGitStatus() {
+	if outputFile != "" {
+		cobra.CheckErr("--watch cannot be used with --output-file, as the status is redrawn in the terminal")
+	}
+
+	if formatTemplate != "" || !redrawnFormat() && !strings.EqualFold(outputFormat, output.JSONFormat) {
+		cobra.CheckErr("--watch can only be used with --output table, text or json, as the status is redrawn in the terminal")
+	}
+
+	// Log messages are written as they happen, so there is nothing to close
+	logger := output.SStdOut{
+		Out:   os.Stdout,
+		Level: outputLevel(),
+	}
+
+	gitStatus := git.Status{
+		Outputter:  logger,
+		Strict:     strict,
+		DiffStat:   showDiffStat,
+		Divergence: true,
+		Base:       baseRef,
+		Manifest:   manifestDefaultBranches(),
+	}
+
+	watcher, err := watch.NewWatcher(WorkingDir, logger)
+	cobra.CheckErr(err)
+	defer watcher.Close()
+
+	statuses := map[string]watchedStatus{}
+
+	update := func(repositoryName string) {
+		repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+		info, err := os.Stat(repositoryDir)
+		if err != nil || !info.IsDir() {
+			delete(statuses, repositoryName)
+			watcher.RemoveRepository(repositoryName)
+			return
+		}
+
+		if isGitRepository(repositoryDir) {
+			if err := watcher.AddRepository(repositoryName); err != nil {
+				logger.Error(fmt.Sprintf("%-50s Unable to watch git repository: %s", repositoryName, err.Error()))
 			}
+		}
 
-			message := createMessage(repositoryStatus, out)
+		status, err := ExecuteGitStatus(repositoryDir, gitStatus)
+		statuses[repositoryName] = watchedStatus{status, err}
+	}
+
+	entries, err := os.ReadDir(WorkingDir)
+	cobra.CheckErr(err)
 
-			formattedMessage := fmt.Sprintf("%-50s %-30s %-30s %s", repositoryName, repositoryStatus.LocalBranch, repositoryStatus.VersionNumber, message)
-			out.Success(formattedMessage)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			update(entry.Name())
 		}
-	},
+	}
+
+	printWatchedStatus(statuses)
+
+	err = watcher.Run(func(repositoryNames []string) {
+		for _, repositoryName := range repositoryNames {
+			update(repositoryName)
+		}
+
+		printWatchedStatus(statuses)
+	})
+	cobra.CheckErr(err)
+}
+
+// printWatchedStatus writes the status of every repository with a new
+// Outputter, so table output is redrawn in full.
+func printWatchedStatus(statuses map[string]watchedStatus) {
+	var names []string
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := newOutputter(statusFieldNames, defaultStatusColumns...)
+
+	if redrawnFormat() && output.IsTerminal(os.Stdout) {
+		// Clear the screen and move the cursor to the top left
+		fmt.Print("\033[H\033[2J")
+	}
+
+	summary := output.NewSummary()
+
+	for _, name := range names {
+		out.Result(statusResult(name, statuses[name].status, statuses[name].err))
+		countStatus(summary, statuses[name].status, statuses[name].err)
+	}
+
+	// Elapsed time is left out as it has no meaning when watching
+	out.Summary(*summary)
+	out.Info(fmt.Sprintf("Watching %d directories, updated %s. Press Ctrl+C to exit.", len(names), time.Now().Format("15:04:05")))
+
+	cobra.CheckErr(out.Close())
+}
+
+// redrawnFormat reports whether the output format is written for a terminal, so
+// the screen can be cleared before the status is written again.
+func redrawnFormat() bool {
+	return strings.EqualFold(outputFormat, output.TableFormat) || strings.EqualFold(outputFormat, output.TextFormat)
+}
+
+// countStatus adds the local and remote status of a repository to the summary,
+// counting a repository with no local or remote changes once as up to date.
+func countStatus(summary *output.Summary, repositoryStatus git.RepositoryStatus, err error) {
+	switch {
+	case err != nil:
+		summary.CountError()
+	case repositoryStatus.LocalStatus == git.NotVersioned:
+		summary.CountNotVersioned()
+	case repositoryStatus.LocalStatus == git.NoChanges && repositoryStatus.RemoteStatus == git.NoChanges:
+		summary.Count(git.NoChanges.Message)
+	default:
+		if repositoryStatus.LocalStatus != git.NoChanges {
+			summary.Count(repositoryStatus.LocalStatus.Message)
+		}
+
+		if repositoryStatus.RemoteStatus != git.NoChanges {
+			summary.Count(repositoryStatus.RemoteStatus.Message)
+		}
+	}
 }
 
 func ExecuteGitStatus(repositoryDir string, gitStatus git.Status) (git.RepositoryStatus, error) {
@@ -93,12 +378,12 @@ func ExecuteGitStatus(repositoryDir string, gitStatus git.Status) (git.Repositor
 	return status, nil
 }
 
-func createMessage(repositoryStatus git.RepositoryStatus, out output.Outputter) string {
+func createMessage(repositoryStatus git.RepositoryStatus) string {
 	var message string
 
 	if repositoryStatus.LocalStatus == git.NotVersioned ||
 		repositoryStatus.LocalStatus == repositoryStatus.RemoteStatus {
-		return repositoryStatus.LocalStatus.Color.Render(repositoryStatus.LocalStatus.Message)
+		return repositoryStatus.LocalStatus.Message
 	}
 
 	if repositoryStatus.LocalStatus != git.NoChanges {
@@ -110,14 +395,19 @@ func createMessage(repositoryStatus git.RepositoryStatus, out output.Outputter)
 			message += ", "
 		}
 
-		message = repositoryStatus.RemoteStatus.Message
+		message += repositoryStatus.RemoteStatus.Message
 	}
 
-	return out.RenderWarn(message)
+	return message
 }
 
 func init() {
 	gitCmd.AddCommand(statusCmd)
 
 	statusCmd.PersistentFlags().BoolVarP(&strict, "strict", "s", false, "treat untracked files as outstanding changes")
+	statusCmd.PersistentFlags().BoolVar(&showFiles, "files", false, "list the changed files of each repository")
+	statusCmd.PersistentFlags().BoolVar(&showDiffStat, "diffstat", false, "list the changed files with the lines added and removed")
+	statusCmd.PersistentFlags().StringVar(&baseRef, "base", "",
+		"ref to count commits ahead and behind from, in addition to the upstream (default is the default branch)")
+	statusCmd.PersistentFlags().BoolVar(&watchStatus, "watch", false, "keep the status up to date as repositories change")
 }
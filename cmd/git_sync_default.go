@@ -0,0 +1,171 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const syncDefaultOperation = "sync-default"
+
+var syncDefaultFieldNames = []string{"branch", "previous"}
+
+var syncDefaultCmd = &cobra.Command{
+	Use:   "sync-default",
+	Short: "Returns all sub-directories to their default branch and fast-forwards it",
+	Long: `Returns all sub-directories to their default branch and fast-forwards it.
+
+Repositories with uncommitted changes, or on a branch with commits that have not
+been pushed or whose upstream has been deleted, are left on their current branch
+so no work is lost.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		out := newOutputter(syncDefaultFieldNames)
+
+		gitStatus := git.Status{
+			Outputter:  out,
+			Divergence: true,
+			Manifest:   manifestDefaultBranches(),
+		}
+
+		gitCheckout := git.Checkout{
+			Outputter: out,
+		}
+
+		gitPull := git.Pull{
+			Outputter: out,
+		}
+
+		summary := output.NewSummary()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, syncDefaultOperation)
+				continue
+			}
+
+			repositoryStatus, err := gitStatus.Exec(repositoryDir)
+			if err != nil {
+				reportError(out, summary, repositoryName, syncDefaultOperation, "Unable to read git repository", err)
+				continue
+			}
+
+			result := output.Result{
+				Repository: repositoryName,
+				Operation:  syncDefaultOperation,
+				Data:       repositoryStatus,
+				Fields:     []output.Field{{Name: "branch", Value: repositoryStatus.LocalBranch}},
+			}
+
+			defaultBranch := git.LocalBranchName(strings.TrimPrefix(repositoryStatus.DefaultBranch, "origin/"))
+
+			if reason := syncBlocked(repositoryStatus); reason != "" {
+				result.Outcome = output.Warning
+				result.Message = reason
+				report(out, summary, result)
+				continue
+			}
+
+			switched := repositoryStatus.LocalBranch != string(defaultBranch)
+
+			if switched {
+				if err := gitCheckout.Exec(repositoryDir, defaultBranch); err != nil {
+					reportError(out, summary, repositoryName, syncDefaultOperation, "Unable to checkout "+string(defaultBranch), err)
+					continue
+				}
+
+				result.Fields[0].Value = string(defaultBranch)
+			}
+
+			if err := gitPull.ExecFastForward(repositoryDir); err != nil {
+				reportError(out, summary, repositoryName, syncDefaultOperation, "Unable to fast-forward "+string(defaultBranch), err)
+				continue
+			}
+
+			result.Outcome = output.OK
+			result.Message = "Default branch up to date"
+
+			if switched {
+				result.Message = "Switched to default branch"
+				result.Fields = append(result.Fields, output.Field{Name: "previous", Value: repositoryStatus.LocalBranch})
+			}
+
+			report(out, summary, result)
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// syncBlocked returns why the repository must be left on its current branch,
+// or an empty string when it can be synced.
+func syncBlocked(repositoryStatus git.RepositoryStatus) string {
+	onDefault := "origin/"+repositoryStatus.LocalBranch == repositoryStatus.DefaultBranch
+
+	switch {
+	case repositoryStatus.DefaultBranch == "":
+		return "Unable to detect default branch"
+	case repositoryStatus.LocalStatus == git.UncommittedChanges:
+		return "Left on branch: uncommitted changes"
+	case onDefault:
+		// Fast-forwarding would fail, so the commits are reported rather than the error
+		if repositoryStatus.CommitsAhead > 0 {
+			return "Left on default branch: unpushed commits"
+		}
+	case repositoryStatus.UpstreamGone:
+		// The commits ahead of a deleted upstream are not counted, so any could be unique
+		return "Left on branch: upstream is gone"
+	case repositoryStatus.RemoteBranch == "":
+		// Detached HEADs and branches that have never been pushed
+		if repositoryStatus.BaseBranch == "" {
+			return "Left on branch: unable to compare with default branch"
+		}
+		if repositoryStatus.CommitsAheadBase > 0 {
+			return "Left on branch: unpushed commits"
+		}
+	case repositoryStatus.CommitsAhead > 0:
+		return "Left on branch: unpushed commits"
+	}
+
+	return ""
+}
+
+func init() {
+	gitCmd.AddCommand(syncDefaultCmd)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncBlocked(t *testing.T) {
+	onFeature := git.RepositoryStatus{
+		LocalBranch:   "feature",
+		RemoteBranch:  "origin/feature",
+		LocalStatus:   git.NoChanges,
+		DefaultBranch: "origin/main",
+		BaseBranch:    "origin/main",
+	}
+
+	tests := []struct {
+		name   string
+		status func(s git.RepositoryStatus) git.RepositoryStatus
+		reason string
+	}{
+		{"pushed branch", func(s git.RepositoryStatus) git.RepositoryStatus {
+			return s
+		}, ""},
+		{"unknown default branch", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.DefaultBranch = ""
+			return s
+		}, "Unable to detect default branch"},
+		{"uncommitted changes", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.LocalStatus = git.UncommittedChanges
+			return s
+		}, "Left on branch: uncommitted changes"},
+		{"unpushed commits", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.CommitsAhead = 1
+			return s
+		}, "Left on branch: unpushed commits"},
+		{"upstream gone", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.UpstreamGone = true
+			return s
+		}, "Left on branch: upstream is gone"},
+		{"never pushed and merged", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.RemoteBranch = ""
+			return s
+		}, ""},
+		{"never pushed with commits", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.RemoteBranch = ""
+			s.CommitsAheadBase = 2
+			return s
+		}, "Left on branch: unpushed commits"},
+		{"never pushed with unknown base", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.RemoteBranch = ""
+			s.BaseBranch = ""
+			return s
+		}, "Left on branch: unable to compare with default branch"},
+		{"default branch", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.LocalBranch, s.RemoteBranch = "main", "origin/main"
+			return s
+		}, ""},
+		{"default branch with unpushed commits", func(s git.RepositoryStatus) git.RepositoryStatus {
+			s.LocalBranch, s.RemoteBranch = "main", "origin/main"
+			s.CommitsAhead = 1
+			return s
+		}, "Left on default branch: unpushed commits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			reason := syncBlocked(tt.status(onFeature))
+
+			// Then
+			assert.Equal(t, reason, tt.reason)
+		})
+	}
+}
@@ -0,0 +1,378 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"github.com/klyall/kl-cli/pkg/edit"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const replaceOperation = "replace"
+
+var replaceGlobs []string
+var replaceRegexp bool
+var replaceDryRun bool
+var replaceBranch string
+var replaceMessage string
+
+var defaultReplaceColumns = []string{"status", "name", "files", "replacements", "branch", "message"}
+var replaceFieldNames = []string{"files", "replacements", "branch"}
+
+// replacedFile is a tracked file that had replacements made in it.
+type replacedFile struct {
+	Path         string `json:"path"`
+	Replacements int    `json:"replacements"`
+	Diff         string `json:"diff"`
+}
+
+type replaceSummary struct {
+	Files     []replacedFile `json:"files"`
+	Branch    string         `json:"branch,omitempty"`
+	Committed bool           `json:"committed"`
+}
+
+var replaceCmd = &cobra.Command{
+	Use:   "replace <old> <new>",
+	Short: "Replaces a string in the tracked files of all sub-directories",
+	Long: `Replaces a string in the tracked files of all sub-directories, showing the changes
+made to each repository as a diff.
+
+The files edited can be limited with --glob, matched against the file name or,
+when the pattern has a '/', the path within the repository, e.g.
+
+  kl replace "example.com/old/log" "example.com/new/log" --glob "*.go" --glob go.mod
+
+With --regexp the string to replace is a regular expression, which the replacement
+can refer to the groups of, e.g. '$1'. Use --dry-run to preview the changes.
+
+With --message the changes are committed, on a new branch when --branch is
+given. Repositories with uncommitted changes are left unchanged when committing.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		replacement, err := edit.NewReplacement(args[0], args[1], replaceRegexp)
+		cobra.CheckErr(err)
+
+		for _, glob := range replaceGlobs {
+			if _, err := path.Match(glob, ""); err != nil {
+				cobra.CheckErr("invalid glob '" + glob + "'")
+			}
+		}
+
+		if replaceBranch != "" && replaceMessage == "" {
+			cobra.CheckErr("--branch needs a commit --message")
+		}
+
+		out := newOutputter(replaceFieldNames, defaultReplaceColumns...)
+
+		if replaceBranch != "" {
+			cobra.CheckErr(git.Branch{Outputter: out}.CheckBranchName(replaceBranch))
+		}
+
+		gitLsFiles := git.LsFiles{
+			Outputter: out,
+		}
+
+		gitStatus := git.Status{
+			Outputter: out,
+		}
+
+		summary := output.NewSummary()
+
+		// Find directories
+		entries, err := os.ReadDir(WorkingDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Loop through directories
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			repositoryName := entry.Name()
+
+			repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+			if !isGitRepository(repositoryDir) {
+				reportNotVersioned(out, summary, repositoryName, replaceOperation)
+				continue
+			}
+
+			// The branch the changes are committed to
+			branch := replaceBranch
+
+			if replaceMessage != "" {
+				status, err := gitStatus.Exec(repositoryDir)
+				if err != nil {
+					reportError(out, summary, repositoryName, replaceOperation, "Unable to get status", err)
+					continue
+				}
+
+				if status.Staged > 0 || status.Unstaged > 0 {
+					report(out, summary, output.Result{
+						Repository: repositoryName,
+						Operation:  replaceOperation,
+						Outcome:    output.Warning,
+						Message:    "Left unchanged: uncommitted changes",
+					})
+					continue
+				}
+
+				if branch == "" {
+					branch = status.LocalBranch
+				}
+			}
+
+			files, err := gitLsFiles.ExecTracked(repositoryDir)
+			if err != nil {
+				reportError(out, summary, repositoryName, replaceOperation, "Unable to list files", err)
+				continue
+			}
+
+			edits, err := replaceInFiles(repositoryDir, files, replacement)
+			if err != nil {
+				reportError(out, summary, repositoryName, replaceOperation, "Unable to read files", err)
+				continue
+			}
+
+			if len(edits) == 0 {
+				report(out, summary, output.Result{
+					Repository: repositoryName,
+					Operation:  replaceOperation,
+					Outcome:    output.Skipped,
+					Message:    "No matches",
+				})
+				continue
+			}
+
+			if replaceDryRun {
+				result := replaceResult(repositoryName, edits, false)
+				result.Message = "Would replace"
+				report(out, summary, result)
+				continue
+			}
+
+			if replaceBranch != "" {
+				if err := switchToNewBranch(out, repositoryDir, git.LocalBranchName(replaceBranch)); err != nil {
+					reportError(out, summary, repositoryName, replaceOperation, "Unable to create branch", err)
+					continue
+				}
+			}
+
+			if err := writeReplacements(repositoryDir, edits); err != nil {
+				message := "Unable to replace"
+				if replaceBranch != "" {
+					message += ", on branch " + replaceBranch
+				}
+
+				reportError(out, summary, repositoryName, replaceOperation, message, err)
+				continue
+			}
+
+			if replaceMessage != "" {
+				if err := commitFiles(out, repositoryDir, replaceMessage, editedFiles(edits)); err != nil {
+					result := replaceResult(repositoryName, edits, false)
+					result.Outcome = output.Failure
+					result.Message = uncommittedMessage(branch, err)
+					report(out, summary, result)
+					continue
+				}
+			}
+
+			report(out, summary, replaceResult(repositoryName, edits, replaceMessage != ""))
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// fileEdit is the new content of a tracked file.
+type fileEdit struct {
+	file    replacedFile
+	content string
+	mode    os.FileMode
+}
+
+// replaceInFiles returns the edits made by the replacement to the files that
+// match the globs, skipping binary files and those missing from the working tree.
+func replaceInFiles(repositoryDir string, files []string, replacement edit.Replacement) ([]fileEdit, error) {
+	var edits []fileEdit
+
+	for _, file := range files {
+		if !matchGlobs(replaceGlobs, file) {
+			continue
+		}
+
+		filePath := filepath.Join(repositoryDir, filepath.FromSlash(file))
+
+		info, err := os.Lstat(filePath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if edit.IsBinary(content) {
+			continue
+		}
+
+		replaced, count := replacement.Apply(string(content))
+		if count == 0 || replaced == string(content) {
+			continue
+		}
+
+		edits = append(edits, fileEdit{
+			file: replacedFile{
+				Path:         file,
+				Replacements: count,
+				Diff:         edit.UnifiedDiff(file, string(content), replaced),
+			},
+			content: replaced,
+			mode:    info.Mode().Perm(),
+		})
+	}
+
+	return edits, nil
+}
+
+func writeReplacements(repositoryDir string, edits []fileEdit) error {
+	for _, e := range edits {
+		filePath := filepath.Join(repositoryDir, filepath.FromSlash(e.file.Path))
+
+		if err := os.WriteFile(filePath, []byte(e.content), e.mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func editedFiles(edits []fileEdit) []string {
+	var files []string
+
+	for _, e := range edits {
+		files = append(files, e.file.Path)
+	}
+
+	return files
+}
+
+func replaceResult(repositoryName string, edits []fileEdit, committed bool) output.Result {
+	var files []replacedFile
+	var details []string
+	replacements := 0
+
+	for _, e := range edits {
+		files = append(files, e.file)
+		details = append(details, diffDetails(e.file.Diff)...)
+		replacements += e.file.Replacements
+	}
+
+	message := "Replaced"
+	if committed {
+		message = "Committed"
+	}
+
+	return output.Result{
+		Repository: repositoryName,
+		Operation:  replaceOperation,
+		Outcome:    output.OK,
+		Message:    message,
+		Data:       replaceSummary{Files: files, Branch: replaceBranch, Committed: committed},
+		Details:    details,
+		Fields: []output.Field{
+			{Name: "files", Value: strconv.Itoa(len(edits))},
+			{Name: "replacements", Value: strconv.Itoa(replacements)},
+			{Name: "branch", Value: replaceBranch},
+		},
+	}
+}
+
+// diffDetails colours the lines of a diff, as with 'git diff'.
+func diffDetails(diff string) []string {
+	var lines []string
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			line = output.InfoColor.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = output.DebugColor.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = output.SuccessColor.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = output.ErrorColor.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// matchGlobs reports whether the file matches any of the globs, or there are
+// none. Globs without a '/' are matched against the name of the file.
+func matchGlobs(globs []string, file string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+
+	for _, glob := range globs {
+		name := file
+		if !strings.Contains(glob, "/") {
+			name = path.Base(file)
+		}
+
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(replaceCmd)
+
+	replaceCmd.PersistentFlags().StringSliceVarP(&replaceGlobs, "glob", "g", nil, "only edit files matching the glob, e.g. '*.go'")
+	replaceCmd.PersistentFlags().BoolVarP(&replaceRegexp, "regexp", "E", false, "replace matches of a regular expression")
+	replaceCmd.PersistentFlags().BoolVarP(&replaceDryRun, "dry-run", "d", false, "show what would be done, without making any changes.")
+	replaceCmd.PersistentFlags().StringVarP(&replaceBranch, "branch", "b", "", "commit the changes on a new branch")
+	replaceCmd.PersistentFlags().StringVarP(&replaceMessage, "message", "m", "", "commit the changes with the message")
+}
@@ -16,17 +16,27 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/klyall/kl-cli/pkg/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
 var WorkingDir string
-var Verbose bool
+var verbosity int
+var quiet bool
+var colorMode string
+var outputFormat string
+var columnNames []string
+var formatTemplate string
+var outputFile string
+var failOn []string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -58,8 +68,18 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.kl.yaml)")
-	rootCmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "verbose output, repeat (-vv) to trace every git command")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only output problems")
 	rootCmd.PersistentFlags().StringVarP(&WorkingDir, "working-dir", "w", currentDir, "working directory")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", string(output.ColorAuto), "when to use colour: auto, always or never")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", output.TableFormat, "output format: "+strings.Join(output.Formats, ", "))
+	rootCmd.PersistentFlags().StringSliceVar(&columnNames, "columns", nil, "columns of the table output to show, in order")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "write the results to a file instead of the terminal")
+	rootCmd.PersistentFlags().StringSliceVar(&failOn, "fail-on", nil,
+		"conditions reported as failures by the junit output, from: "+strings.Join(output.FailOnConditions, ", "))
+	rootCmd.PersistentFlags().StringVar(&formatTemplate, "format", "", "write each result with a Go template, e.g. '{{.Name}} {{.Message}}'")
+
+	cobra.CheckErr(viper.BindPFlag("color", rootCmd.PersistentFlags().Lookup("color")))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -89,4 +109,117 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 
+	initColor()
+}
+
+// newOutputter creates the Outputter for the --output format, or the --format
+// template, at the level set by the --quiet and --verbose flags. The default
+// columns are used when none are given with --columns, which must otherwise be
+// built in columns or the fields of the command's results.
+func newOutputter(fields []string, defaultColumns ...string) output.Outputter {
+	columns := columnNames
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+
+	// Checked before the --output-file is created
+	cobra.CheckErr(output.ValidateColumns(columns, fields))
+	cobra.CheckErr(validateFailOn(fields))
+
+	if outputFile != "" {
+		return newFileOutputter(columns, fields)
+	}
+
+	out, err := output.New(outputFormat, os.Stdout, os.Stderr, outputOptions(columns, fields))
+	cobra.CheckErr(err)
+
+	return out
+}
+
+// fileOutputter closes the --output-file once the results have been written.
+type fileOutputter struct {
+	output.Outputter
+	file *os.File
+}
+
+// newFileOutputter writes the results to the --output-file, with log messages
+// written to the terminal.
+func newFileOutputter(columns []string, fields []string) output.Outputter {
+	file, err := os.Create(outputFile)
+	cobra.CheckErr(err)
+
+	// Colour is only used in a file when forced with --color=always
+	mode, err := output.ParseColorMode(viper.GetString("color"))
+	cobra.CheckErr(err)
+	output.SetColorMode(mode, file)
+
+	out, err := output.New(outputFormat, file, os.Stdout, outputOptions(columns, fields))
+	if err != nil {
+		file.Close()
+		cobra.CheckErr(err)
+	}
+
+	return fileOutputter{out, file}
+}
+
+func (f fileOutputter) Close() error {
+	err := f.Outputter.Close()
+
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func outputOptions(columns []string, fields []string) output.Options {
+	return output.Options{
+		Level:    outputLevel(),
+		Columns:  columns,
+		Fields:   fields,
+		Template: formatTemplate,
+		FailOn:   failOn,
+	}
+}
+
+// validateFailOn checks the --fail-on conditions, which are only used by the
+// junit output and must be read from fields the command reports.
+func validateFailOn(fields []string) error {
+	if len(failOn) == 0 {
+		return nil
+	}
+
+	if formatTemplate != "" || !strings.EqualFold(outputFormat, output.JUnitFormat) {
+		return errors.New("--fail-on can only be used with --output junit")
+	}
+
+	return output.ValidateFailOnFields(failOn, fields)
+}
+
+func outputLevel() output.Level {
+	if !quiet {
+		return output.Level(verbosity)
+	}
+
+	if verbosity > 0 {
+		cobra.CheckErr("--quiet and --verbose cannot be used together")
+	}
+
+	return output.QuietLevel
+}
+
+// initColor applies the colour mode from the --color flag or config file, and
+// any colours overridden in the theme section of the config file, e.g.
+//
+//	color: auto
+//	theme:
+//	  warn: magenta
+//	  success: green
+func initColor() {
+	cobra.CheckErr(output.SetTheme(viper.GetStringMapString("theme")))
+
+	mode, err := output.ParseColorMode(viper.GetString("color"))
+	cobra.CheckErr(err)
+
+	output.SetColorMode(mode, os.Stdout)
 }
@@ -0,0 +1,410 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/klyall/kl-cli/pkg/snapshot"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const snapshotSaveOperation = "snapshot save"
+const snapshotRestoreOperation = "snapshot restore"
+const snapshotDiffOperation = "snapshot diff"
+
+var snapshotDryRun bool
+
+var defaultSnapshotColumns = []string{"status", "name", "branch", "sha", "dirty", "message"}
+var defaultSnapshotDiffColumns = []string{"status", "name", "from", "to", "message"}
+var snapshotFieldNames = []string{"branch", "sha", "dirty"}
+var snapshotDiffFieldNames = []string{"from", "to"}
+
+var errNotVersioned = errors.New("not versioned")
+var errNoCommits = errors.New("no commits")
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Saves and restores the commits checked out in all sub-directories",
+	Long: `Saves and restores the commits checked out in all sub-directories, e.g. to
+reproduce a bug with the exact versions of every service.
+
+A snapshot records the branch, commit, remote and whether there were uncommitted
+changes for each repository. Uncommitted changes are not saved.`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <file>",
+	Short: "Saves the commits checked out in all sub-directories to a file",
+	Long:  `Saves the commits checked out in all sub-directories to a file.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		out := newOutputter(snapshotFieldNames, defaultSnapshotColumns...)
+
+		summary := output.NewSummary()
+
+		saved := workspaceSnapshot(out, func(repositoryName string, repository snapshot.Repository, err error) {
+			switch {
+			case errors.Is(err, errNotVersioned):
+				reportNotVersioned(out, summary, repositoryName, snapshotSaveOperation)
+			case errors.Is(err, errNoCommits):
+				report(out, summary, output.Result{
+					Repository: repositoryName,
+					Operation:  snapshotSaveOperation,
+					Outcome:    output.Skipped,
+					Message:    "No commits",
+				})
+			case err != nil:
+				reportError(out, summary, repositoryName, snapshotSaveOperation, "Unable to save repository", err)
+			default:
+				result := snapshotResult(repositoryName, snapshotSaveOperation, repository, "Saved")
+				if repository.Dirty {
+					result.Outcome = output.Warning
+					result.Message = "Saved without uncommitted changes"
+				}
+				report(out, summary, result)
+			}
+		})
+
+		err := saved.Save(args[0])
+		if err == nil {
+			out.Info(fmt.Sprintf("Snapshot saved to %s", args[0]))
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+
+		// Reported after the results, failing the command as nothing was saved
+		if err != nil {
+			cobra.CheckErr(fmt.Errorf("unable to save snapshot: %w", err))
+		}
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Checks out the commits of a snapshot in all sub-directories",
+	Long: `Checks out the commits of a snapshot in all sub-directories.
+
+The branch saved is checked out when it is still at the commit saved, otherwise
+the commit is checked out with a detached HEAD. Repositories with uncommitted
+changes are left unchanged, and commits that are not found need to be fetched
+first with 'kl git fetch'. A warning is reported when the remote of a repository
+differs from the one saved.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		saved, err := snapshot.Load(args[0])
+		cobra.CheckErr(err)
+
+		out := newOutputter(snapshotFieldNames, defaultSnapshotColumns...)
+
+		summary := output.NewSummary()
+
+		for _, repository := range saved.Repositories {
+			report(out, summary, restoreRepository(out, repository))
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff <from-file> [<to-file>]",
+	Short: "Compares two snapshots, or a snapshot with the sub-directories",
+	Long: `Compares two snapshots, or a snapshot with the commits checked out in the
+sub-directories when only one is given.
+
+Each repository is reported as unchanged, moved to another branch or commit,
+added or removed.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		from, err := snapshot.Load(args[0])
+		cobra.CheckErr(err)
+
+		out := newOutputter(snapshotDiffFieldNames, defaultSnapshotDiffColumns...)
+
+		summary := output.NewSummary()
+
+		var to snapshot.Snapshot
+		if len(args) > 1 {
+			to, err = snapshot.Load(args[1])
+			cobra.CheckErr(err)
+		} else {
+			to = workspaceSnapshot(out, func(repositoryName string, repository snapshot.Repository, err error) {
+				if err != nil && !errors.Is(err, errNotVersioned) && !errors.Is(err, errNoCommits) {
+					reportError(out, summary, repositoryName, snapshotDiffOperation, "Unable to read repository", err)
+				}
+			})
+		}
+
+		for _, change := range snapshot.Compare(from, to) {
+			report(out, summary, snapshotDiffResult(change))
+		}
+
+		out.Summary(summary.Finish())
+		cobra.CheckErr(out.Close())
+	},
+}
+
+// workspaceSnapshot records the commit checked out in each sub-directory,
+// calling saved with the repository or the error for each one.
+func workspaceSnapshot(out output.Outputter, saved func(repositoryName string, repository snapshot.Repository, err error)) snapshot.Snapshot {
+	s := snapshot.Snapshot{
+		Created: time.Now(),
+	}
+
+	// Find directories
+	entries, err := os.ReadDir(WorkingDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Loop through directories
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		repositoryName := entry.Name()
+
+		repositoryDir := filepath.Join(WorkingDir, repositoryName)
+
+		if !isGitRepository(repositoryDir) {
+			saved(repositoryName, snapshot.Repository{}, errNotVersioned)
+			continue
+		}
+
+		repository, err := snapshotRepository(out, repositoryDir, repositoryName)
+		if err == nil {
+			s.Repositories = append(s.Repositories, repository)
+		}
+
+		saved(repositoryName, repository, err)
+	}
+
+	return s
+}
+
+func snapshotRepository(out output.Outputter, repositoryDir, repositoryName string) (snapshot.Repository, error) {
+	status, err := git.Status{Outputter: out}.Exec(repositoryDir)
+	if err != nil {
+		return snapshot.Repository{}, err
+	}
+
+	sha, err := git.RevParse{Outputter: out}.ExecCommit(repositoryDir, "HEAD")
+	if err != nil {
+		return snapshot.Repository{}, errNoCommits
+	}
+
+	repository := snapshot.Repository{
+		Name:   repositoryName,
+		Branch: checkedOutBranch(status),
+		SHA:    sha,
+		Dirty:  status.Staged > 0 || status.Unstaged > 0,
+	}
+
+	if remote, err := (git.Remote{Outputter: out}).Exec(repositoryDir); err == nil {
+		repository.Remote = remote.Fetch
+	}
+
+	return repository, nil
+}
+
+// checkedOutBranch returns the current branch, or an empty string when HEAD
+// is detached.
+func checkedOutBranch(status git.RepositoryStatus) string {
+	if status.LocalBranch == "HEAD" {
+		return ""
+	}
+	return status.LocalBranch
+}
+
+// restoreRepository checks out the commit saved for the repository. It is
+// reported as a warning when the repository now has a different remote.
+func restoreRepository(out output.Outputter, repository snapshot.Repository) output.Result {
+	result := snapshotResult(repository.Name, snapshotRestoreOperation, repository, "Restored")
+
+	if err := repository.Validate(); err != nil {
+		result.Outcome = output.Failure
+		result.Message = "Invalid snapshot: " + err.Error()
+		return result
+	}
+
+	repositoryDir := filepath.Join(WorkingDir, repository.Name)
+
+	if !isGitRepository(repositoryDir) {
+		result.Outcome = output.Failure
+		result.Message = "Repository not found"
+		return result
+	}
+
+	gitRevParse := git.RevParse{Outputter: out}
+	gitCheckout := git.Checkout{Outputter: out}
+
+	status, err := git.Status{Outputter: out}.Exec(repositoryDir)
+	if err != nil {
+		result.Outcome = output.Failure
+		result.Message = "Unable to get status: " + err.Error()
+		return result
+	}
+
+	if status.Staged > 0 || status.Unstaged > 0 {
+		result.Outcome = output.Warning
+		result.Message = "Left unchanged: uncommitted changes"
+		return result
+	}
+
+	if remote, err := (git.Remote{Outputter: out}).Exec(repositoryDir); err == nil && repository.Remote != "" && remote.Fetch != repository.Remote {
+		result.Outcome = output.Warning
+		result.Details = append(result.Details, fmt.Sprintf("Remote is %s but was %s in the snapshot", remote.Fetch, repository.Remote))
+	}
+
+	if _, err := gitRevParse.ExecCommit(repositoryDir, repository.SHA); err != nil {
+		result.Outcome = output.Failure
+		result.Message = "Commit not found, fetch first"
+		return result
+	}
+
+	head, _ := gitRevParse.ExecCommit(repositoryDir, "HEAD")
+	if head == repository.SHA && checkedOutBranch(status) == repository.Branch {
+		result.Outcome = output.Skipped
+		result.Message = "Already at snapshot"
+		return result
+	}
+
+	// The branch may have moved on since the snapshot was saved
+	branch := repository.Branch
+	if branch != "" {
+		if tip, err := gitRevParse.ExecCommit(repositoryDir, "refs/heads/"+branch); err != nil || tip != repository.SHA {
+			branch = ""
+		}
+	}
+
+	if branch == "" {
+		result.Fields = snapshotFields(snapshot.Repository{SHA: repository.SHA, Dirty: repository.Dirty})
+	}
+
+	if repository.Dirty {
+		result.Details = append(result.Details, "Uncommitted changes at the time of the snapshot are not restored")
+	}
+
+	if snapshotDryRun {
+		result.Message = "Would restore"
+		return result
+	}
+
+	if branch != "" {
+		err = gitCheckout.Exec(repositoryDir, git.LocalBranchName(branch))
+	} else {
+		err = gitCheckout.ExecDetach(repositoryDir, repository.SHA)
+	}
+
+	if err != nil {
+		result.Outcome = output.Failure
+		result.Message = "Unable to checkout: " + err.Error()
+		return result
+	}
+
+	if branch == "" {
+		result.Message = "Restored with detached HEAD"
+	}
+
+	return result
+}
+
+func snapshotResult(repositoryName, operation string, repository snapshot.Repository, message string) output.Result {
+	return output.Result{
+		Repository: repositoryName,
+		Operation:  operation,
+		Outcome:    output.OK,
+		Message:    message,
+		Data:       repository,
+		Fields:     snapshotFields(repository),
+	}
+}
+
+func snapshotFields(repository snapshot.Repository) []output.Field {
+	return []output.Field{
+		{Name: "branch", Value: repository.Branch},
+		{Name: "sha", Value: shortSHA(repository.SHA)},
+		{Name: "dirty", Value: strconv.FormatBool(repository.Dirty)},
+	}
+}
+
+func snapshotDiffResult(change snapshot.Change) output.Result {
+	result := output.Result{
+		Repository: change.Name,
+		Operation:  snapshotDiffOperation,
+		Outcome:    output.Warning,
+		Data:       change,
+		Fields: []output.Field{
+			{Name: "from", Value: snapshotPosition(change.From)},
+			{Name: "to", Value: snapshotPosition(change.To)},
+		},
+	}
+
+	switch change.Kind {
+	case snapshot.Unchanged:
+		result.Outcome = output.OK
+		result.Message = "Unchanged"
+	case snapshot.Moved:
+		result.Message = "Moved"
+	case snapshot.Added:
+		result.Message = "Added"
+	case snapshot.Removed:
+		result.Message = "Removed"
+	}
+
+	return result
+}
+
+// snapshotPosition describes the commit of a repository, e.g. main@2f1c5c0.
+func snapshotPosition(repository *snapshot.Repository) string {
+	if repository == nil {
+		return ""
+	}
+
+	if repository.Branch == "" {
+		return shortSHA(repository.SHA)
+	}
+
+	return repository.Branch + "@" + shortSHA(repository.SHA)
+}
+
+func shortSHA(sha string) string {
+	return git.LogEntry{SHA: sha}.ShortSHA()
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+
+	snapshotRestoreCmd.Flags().BoolVarP(&snapshotDryRun, "dry-run", "d", false, "show what would be done, without making any changes.")
+}
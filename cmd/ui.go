@@ -0,0 +1,60 @@
+/*
+Copyright © 2021 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/klyall/kl-cli/pkg/ui"
+	"io"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Interactive dashboard of all sub-directories",
+	Long: `Shows a full screen dashboard of the git status of all sub-directories.
+
+Select a repository to see its files, branches and remote, and fetch, pull,
+push or purge it with a single key.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		gitStatus := git.Status{
+			// Output would corrupt the dashboard so it is discarded
+			Outputter: output.SStdOut{Out: io.Discard},
+			Strict:    strict,
+		}
+
+		dashboard := ui.Dashboard{
+			Dir: WorkingDir,
+			Status: func(repositoryDir string) (git.RepositoryStatus, error) {
+				return ExecuteGitStatus(repositoryDir, gitStatus)
+			},
+		}
+
+		if err := dashboard.Run(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	uiCmd.PersistentFlags().BoolVarP(&strict, "strict", "s", false, "treat untracked files as outstanding changes")
+}
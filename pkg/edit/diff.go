@@ -0,0 +1,362 @@
+package edit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// maxSearchSteps limits the search for the shortest edit of very different
+// content, after which a longer edit is used rather than taking seconds.
+const maxSearchSteps = 1024
+
+// maxDiffLines limits the lines of a diff, so the preview of a large change
+// stays readable.
+const maxDiffLines = 1000
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// Line numbers, from 1, in the old and new content
+	oldLine, newLine int
+}
+
+// UnifiedDiff returns the changes from old to new content of the file at path,
+// in the unified format of 'git diff', or an empty string when there are none.
+func UnifiedDiff(path, old, new string) string {
+	if old == new {
+		return ""
+	}
+
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("--- a/%s\n", path))
+	b.WriteString(fmt.Sprintf("+++ b/%s\n", path))
+
+	var lines int
+
+	for _, hunk := range hunks(ops) {
+		// The last hunk shown is cut short, with a header that matches its lines
+		if lines+len(hunk)+1 > maxDiffLines {
+			if remaining := maxDiffLines - lines - 1; remaining > 0 {
+				writeHunk(&b, hunk[:remaining])
+			}
+
+			b.WriteString(fmt.Sprintf("... diff truncated to %d lines\n", maxDiffLines))
+			break
+		}
+
+		writeHunk(&b, hunk)
+		lines += len(hunk) + 1
+	}
+
+	return b.String()
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit from a to b, using the linear space
+// variant of Myers' algorithm, so memory only grows with the number of lines.
+func diffLines(a, b []string) []op {
+	d := differ{a: a, b: b}
+
+	// Lines are compared by id, which is quicker than comparing the strings
+	ids := map[string]int{}
+	d.aIDs, d.bIDs = lineIDs(a, ids), lineIDs(b, ids)
+
+	d.diff(0, len(a), 0, len(b))
+
+	return groupChanges(d.ops)
+}
+
+func lineIDs(lines []string, ids map[string]int) []int {
+	result := make([]int, len(lines))
+
+	for i, line := range lines {
+		id, ok := ids[line]
+		if !ok {
+			id = len(ids)
+			ids[line] = id
+		}
+
+		result[i] = id
+	}
+
+	return result
+}
+
+type differ struct {
+	a, b       []string
+	aIDs, bIDs []int
+	ops        []op
+}
+
+// diff adds the edit from a[aLo:aHi] to b[bLo:bHi], splitting it at a point on
+// the shortest edit until only insertions or deletions are left.
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.aIDs[aLo] == d.bIDs[bLo] {
+		d.ops = append(d.ops, op{opEqual, d.a[aLo], aLo + 1, bLo + 1})
+		aLo++
+		bLo++
+	}
+
+	var suffix int
+	for aLo < aHi && bLo < bHi && d.aIDs[aHi-1] == d.bIDs[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			d.ops = append(d.ops, op{opInsert, d.b[y], aLo, y + 1})
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			d.ops = append(d.ops, op{opDelete, d.a[x], x + 1, bLo})
+		}
+	default:
+		x, y := d.middle(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		d.diff(x, aHi, y, bHi)
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.ops = append(d.ops, op{opEqual, d.a[aHi+i], aHi + i + 1, bHi + i + 1})
+	}
+}
+
+// middle returns a point on the shortest edit from a[aLo:aHi] to b[bLo:bHi]
+// found by searching from both ends at once until the searches overlap, or the
+// furthest point reached forwards once the search is too long. The ranges must
+// differ at both ends, so the point is never at either end.
+func (d *differ) middle(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+
+	max := (n + m + 1) / 2
+	offset := max + 1
+
+	// The furthest x reached on each diagonal k = x - y, forwards from the
+	// start and backwards from the end, where x and y count from the end
+	forward := make([]int, 2*max+3)
+	backward := make([]int, 2*max+3)
+
+	for step := 0; step <= max; step++ {
+		if step > maxSearchSteps {
+			return d.furthest(forward, offset, step-1, n, m, aLo, bLo)
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && d.aIDs[aLo+x] == d.bIDs[bLo+y] {
+				x++
+				y++
+			}
+
+			forward[offset+k] = x
+
+			// The backward diagonal that meets this one, searched one step fewer
+			if back := delta - k; odd && back >= -(step-1) && back <= step-1 && x+backward[offset+back] >= n {
+				return aLo + x, bLo + y
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && d.aIDs[aHi-1-x] == d.bIDs[bHi-1-y] {
+				x++
+				y++
+			}
+
+			backward[offset+k] = x
+
+			if ahead := delta - k; !odd && ahead >= -step && ahead <= step && x+forward[offset+ahead] >= n {
+				return aHi - x, bHi - y
+			}
+		}
+	}
+
+	// Not reached, as the searches overlap by the time each has taken half of
+	// the longest possible edit
+	return aLo, bLo
+}
+
+// furthest returns the point furthest from the start reached by the forward
+// search after the given number of steps, within the n by m ranges.
+func (d *differ) furthest(forward []int, offset, steps, n, m, aLo, bLo int) (int, int) {
+	bestX, bestY := 0, 0
+
+	for k := -steps; k <= steps; k += 2 {
+		x := forward[offset+k]
+		if y := x - k; x <= n && y >= 0 && y <= m && x+y > bestX+bestY {
+			bestX, bestY = x, y
+		}
+	}
+
+	return aLo + bestX, bLo + bestY
+}
+
+// groupChanges puts the deletions of each run of changes before its
+// insertions, as shown by git.
+func groupChanges(ops []op) []op {
+	grouped := make([]op, 0, len(ops))
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			grouped = append(grouped, ops[i])
+			i++
+			continue
+		}
+
+		// Lines before the run in the old and new content
+		oldLine, newLine := ops[i].oldLine, ops[i].newLine
+		if ops[i].kind == opDelete {
+			oldLine--
+		} else {
+			newLine--
+		}
+
+		var deleted, inserted []string
+		for ; i < len(ops) && ops[i].kind != opEqual; i++ {
+			if ops[i].kind == opDelete {
+				deleted = append(deleted, ops[i].line)
+			} else {
+				inserted = append(inserted, ops[i].line)
+			}
+		}
+
+		for j, line := range deleted {
+			grouped = append(grouped, op{opDelete, line, oldLine + j + 1, newLine})
+		}
+
+		for j, line := range inserted {
+			grouped = append(grouped, op{opInsert, line, oldLine + len(deleted), newLine + j + 1})
+		}
+	}
+
+	return grouped
+}
+
+// hunks groups the changes with the unchanged lines around them, joining
+// changes that are close enough for their context to overlap.
+func hunks(ops []op) [][]op {
+	var hunks [][]op
+	start, end := -1, -1
+
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+
+		from := i - contextLines
+		if from < 0 {
+			from = 0
+		}
+
+		if start != -1 && from > end {
+			hunks = append(hunks, ops[start:end])
+			start = -1
+		}
+
+		if start == -1 {
+			start = from
+		}
+
+		end = i + contextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+	}
+
+	if start != -1 {
+		hunks = append(hunks, ops[start:end])
+	}
+
+	return hunks
+}
+
+func writeHunk(b *strings.Builder, hunk []op) {
+	var oldStart, newStart, oldCount, newCount int
+
+	for _, o := range hunk {
+		switch o.kind {
+		case opEqual:
+			oldCount++
+			newCount++
+		case opDelete:
+			oldCount++
+		case opInsert:
+			newCount++
+		}
+	}
+
+	// Line numbers of the first line of the hunk, or the line before when
+	// there are none, as with git
+	first := hunk[0]
+	switch first.kind {
+	case opEqual:
+		oldStart, newStart = first.oldLine, first.newLine
+	case opDelete:
+		oldStart, newStart = first.oldLine, first.newLine+1
+	case opInsert:
+		oldStart, newStart = first.oldLine+1, first.newLine
+	}
+
+	if oldCount == 0 {
+		oldStart--
+	}
+
+	if newCount == 0 {
+		newStart--
+	}
+
+	b.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount)))
+
+	for _, o := range hunk {
+		b.WriteString(string(o.kind) + o.line + "\n")
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,81 @@
+package edit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	// Given
+	old := "package main\n\nimport \"example.com/old/log\"\n\nfunc main() {\n\tlog.Print(\"a\")\n}\n"
+	new := "package main\n\nimport \"example.com/new/log\"\n\nfunc main() {\n\tlog.Print(\"a\")\n}\n"
+
+	// When
+	diff := UnifiedDiff("main.go", old, new)
+
+	// Then
+	assert.Equal(t, diff, "--- a/main.go\n"+
+		"+++ b/main.go\n"+
+		"@@ -1,6 +1,6 @@\n"+
+		" package main\n"+
+		" \n"+
+		"-import \"example.com/old/log\"\n"+
+		"+import \"example.com/new/log\"\n"+
+		" \n"+
+		" func main() {\n"+
+		" \tlog.Print(\"a\")\n")
+}
+
+func TestUnifiedDiffSplitsDistantChanges(t *testing.T) {
+	// Given
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "A\nb\nc\nd\ne\nf\ng\nh\ni\nJ\n"
+
+	// When
+	diff := UnifiedDiff("letters.txt", old, new)
+
+	// Then
+	assert.Contains(t, diff, "@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n d\n")
+	assert.Contains(t, diff, "@@ -7,4 +7,4 @@\n g\n h\n i\n-j\n+J\n")
+}
+
+func TestUnifiedDiffWithoutChanges(t *testing.T) {
+	// When
+	diff := UnifiedDiff("same.txt", "a\n", "a\n")
+
+	// Then
+	assert.Equal(t, diff, "")
+}
+
+func TestUnifiedDiffGroupsDeletionsBeforeInsertions(t *testing.T) {
+	// Given
+	old := "a\nb\nc\nd\n"
+	new := "a\nB\nC\nd\n"
+
+	// When
+	diff := UnifiedDiff("letters.txt", old, new)
+
+	// Then
+	assert.Contains(t, diff, "@@ -1,4 +1,4 @@\n a\n-b\n-c\n+B\n+C\n d\n")
+}
+
+func TestUnifiedDiffTruncatesLargeDiffs(t *testing.T) {
+	// Given
+	var old, new strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&old, "registry.old.example/pkg-%d\n", i)
+		fmt.Fprintf(&new, "registry.new.example/pkg-%d\n", i)
+	}
+
+	// When
+	diff := UnifiedDiff("package-lock.json", old.String(), new.String())
+
+	// Then
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	assert.Equal(t, len(lines), maxDiffLines+3)
+	assert.Equal(t, lines[2], "@@ -1,999 +0,0 @@")
+	assert.Equal(t, lines[len(lines)-1], "... diff truncated to 1000 lines")
+}
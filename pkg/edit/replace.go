@@ -0,0 +1,70 @@
+package edit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Replacement replaces a string, or the matches of a regular expression, in
+// the content of a file.
+type Replacement struct {
+	Old string
+	New string
+
+	re *regexp.Regexp
+}
+
+// NewReplacement replaces old with new. When useRegexp is set old is a regular
+// expression and new can refer to its groups, e.g. $1.
+func NewReplacement(old, new string, useRegexp bool) (Replacement, error) {
+	if old == "" {
+		return Replacement{}, fmt.Errorf("the string to replace cannot be empty")
+	}
+
+	r := Replacement{Old: old, New: new}
+
+	if useRegexp {
+		re, err := regexp.Compile(old)
+		if err != nil {
+			return Replacement{}, fmt.Errorf("invalid regular expression '%s': %w", old, err)
+		}
+		r.re = re
+	}
+
+	return r, nil
+}
+
+// Apply returns the content with the replacements made and how many were made.
+func (r Replacement) Apply(content string) (string, int) {
+	if r.re == nil {
+		count := strings.Count(content, r.Old)
+		if count == 0 {
+			return content, 0
+		}
+		return strings.ReplaceAll(content, r.Old, r.New), count
+	}
+
+	count := len(r.re.FindAllStringIndex(content, -1))
+	if count == 0 {
+		return content, 0
+	}
+
+	return r.re.ReplaceAllString(content, r.New), count
+}
+
+// IsBinary reports whether the content looks like that of a binary file, which
+// is not edited.
+func IsBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+
+	for _, b := range content {
+		if b == 0 {
+			return true
+		}
+	}
+
+	return false
+}
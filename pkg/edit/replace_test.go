@@ -0,0 +1,41 @@
+package edit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplacementApply(t *testing.T) {
+	// Given
+	testee, err := NewReplacement("old/log", "new/log", false)
+	assert.Nil(t, err)
+
+	// When
+	content, count := testee.Apply("import \"old/log\"\nimport \"old/log/v2\"\n")
+
+	// Then
+	assert.Equal(t, content, "import \"new/log\"\nimport \"new/log/v2\"\n")
+	assert.Equal(t, count, 2)
+}
+
+func TestReplacementApplyRegexp(t *testing.T) {
+	// Given
+	testee, err := NewReplacement(`version: (\d+)\.\d+`, "version: $1.5", true)
+	assert.Nil(t, err)
+
+	// When
+	content, count := testee.Apply("version: 1.4\n")
+
+	// Then
+	assert.Equal(t, content, "version: 1.5\n")
+	assert.Equal(t, count, 1)
+}
+
+func TestNewReplacementInvalidRegexp(t *testing.T) {
+	// When
+	_, err := NewReplacement("(", "", true)
+
+	// Then
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,25 @@
+package git
+
+import (
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+)
+
+type Add struct {
+	Outputter output.Outputter
+}
+
+// Exec stages the files, given relative to path.
+func (a Add) Exec(path string, files []string) error {
+	app := "git"
+
+	args := append([]string{"-C", path, "add", "--"}, files...)
+
+	cmd := exec.Command(app, args...)
+
+	out, err := run(a.Outputter, cmd)
+
+	a.Outputter.DebugBytes(out)
+
+	return err
+}
@@ -3,14 +3,16 @@ package git
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"github.com/klyall/kl-cli/pkg/output"
 	"io"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Branch struct {
-	Verbose   bool
 	Outputter output.Outputter
 }
 
@@ -25,90 +27,282 @@ func (b Branch) ExecDelete(path string, branch LocalBranchName) error {
 
 	cmd := exec.Command(app, arg0, arg1, arg2, arg3, string(arg4))
 
-	if b.Verbose {
-		b.Outputter.Debug(cmd)
-	}
+	out, err := run(b.Outputter, cmd)
 
-	out, err := cmd.Output()
+	b.Outputter.DebugBytes(out)
 
-	if b.Verbose {
-		b.Outputter.DebugBytes(out)
-	}
+	return err
+}
+
+// ExecDeleteMerged deletes the branch only when it has been merged into its
+// upstream, or HEAD when it has none.
+func (b Branch) ExecDeleteMerged(path string, branch LocalBranchName) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "branch"
+	arg3 := "-d"
+	arg4 := branch
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, string(arg4))
+
+	out, err := run(b.Outputter, cmd)
+
+	b.Outputter.DebugBytes(out)
 
 	return err
 }
 
-func (b Branch) ExecRemote(path string) ([]RemoteBranchName, error) {
+// ExecCreate creates the branch at the start point without checking it out.
+func (b Branch) ExecCreate(path string, branch LocalBranchName, startPoint string) error {
 	app := "git"
 
 	arg0 := "-C"
 	arg1 := path
 	arg2 := "branch"
-	arg3 := "-r"
+	arg3 := "--no-track"
+	arg4 := branch
+	arg5 := startPoint
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, string(arg4), arg5)
+
+	out, err := run(b.Outputter, cmd)
+
+	b.Outputter.DebugBytes(out)
+
+	return err
+}
+
+func (b Branch) ExecRename(path string, branch LocalBranchName, newBranch LocalBranchName) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "branch"
+	arg3 := "-m"
+	arg4 := branch
+	arg5 := newBranch
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, string(arg4), string(arg5))
+
+	out, err := run(b.Outputter, cmd)
+
+	b.Outputter.DebugBytes(out)
+
+	return err
+}
+
+// ExecExists reports whether the ref, e.g. a branch name or origin/main, names a commit.
+func (b Branch) ExecExists(path string, ref string) bool {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "rev-parse"
+	arg3 := "--verify"
+	arg4 := "--quiet"
+	arg5 := ref + "^{commit}"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	_, err := run(b.Outputter, cmd)
+
+	return err == nil
+}
+
+// ExecCurrent returns the checked out branch, or an empty name when HEAD is
+// detached.
+func (b Branch) ExecCurrent(path string) (LocalBranchName, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "symbolic-ref"
+	arg3 := "--quiet"
+	arg4 := "--short"
+	arg5 := "HEAD"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	out, err := run(b.Outputter, cmd)
+	if exitCode(err) == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return LocalBranchName(strings.TrimSpace(string(out))), nil
+}
+
+// CheckBranchName returns an error when name is not a valid branch name.
+func (b Branch) CheckBranchName(name string) error {
+	app := "git"
 
-	cmd := exec.Command(app, arg0, arg1, arg2, arg3)
+	arg0 := "check-ref-format"
+	arg1 := "--branch"
+	arg2 := name
 
-	if b.Verbose {
-		b.Outputter.Debug(cmd)
+	cmd := exec.Command(app, arg0, arg1, arg2)
+
+	if _, err := run(b.Outputter, cmd); err != nil {
+		return fmt.Errorf("invalid branch name '%s'", name)
 	}
 
-	out, err := cmd.Output()
+	return nil
+}
+
+// ExecRemote returns the names of the remote tracking branches.
+func (b Branch) ExecRemote(path string) ([]RemoteBranchName, error) {
+	remoteBranches, err := b.ExecRemoteBranches(path)
 	if err != nil {
 		return nil, err
 	}
 
-	branches := b.parseBranchRemoteOutput(bytes.NewReader(out))
+	var branches []RemoteBranchName
+	for _, rb := range remoteBranches {
+		branches = append(branches, rb.RemoteBranchName)
+	}
 
 	return branches, nil
 }
 
-func (b Branch) parseBranchRemoteOutput(r io.Reader) []RemoteBranchName {
-	var branches []RemoteBranchName
+// remoteBranchFormat separates the fields of each branch with NUL, which cannot
+// appear in a ref name or author.
+var remoteBranchFormat = strings.Join([]string{
+	"%(refname:short)",
+	"%(symref)",
+	"%(objectname)",
+	"%(committerdate:iso-strict)",
+	"%(authorname)",
+}, "%00")
+
+// ExecRemoteBranches returns the remote tracking branches with their last
+// commit, leaving out symbolic refs such as origin/HEAD.
+func (b Branch) ExecRemoteBranches(path string) ([]RemoteBranch, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "for-each-ref"
+	arg3 := "--format=" + remoteBranchFormat
+	arg4 := "refs/remotes"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4)
+
+	out, err := run(b.Outputter, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.parseRemoteRefsOutput(bytes.NewReader(out))
+}
+
+func (b Branch) parseRemoteRefsOutput(r io.Reader) ([]RemoteBranch, error) {
+	var branches []RemoteBranch
 
 	s := bufio.NewScanner(r)
 
 	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
+		line := s.Text()
+
+		b.Outputter.Debug(strings.ReplaceAll(line, "\x00", " "))
 
-		if b.Verbose {
-			b.Outputter.Debug(s.Text())
+		if line == "" {
+			continue
 		}
 
-		if line != "" {
-			parts := strings.Split(line, " ")
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unable to parse branch '%s'", line)
+		}
 
-			branches = append(branches, RemoteBranchName(parts[0]))
+		if fields[1] != "" {
+			continue
 		}
+
+		date, err := parseCommitDate(fields[0], fields[3])
+		if err != nil {
+			return nil, err
+		}
+
+		branches = append(branches, RemoteBranch{
+			RemoteBranchName: RemoteBranchName(fields[0]),
+			TipSHA:           fields[2],
+			LastCommitDate:   date,
+			LastCommitAuthor: fields[4],
+		})
 	}
 
-	return branches
+	return branches, nil
 }
 
-func (b Branch) ExecVV(path string) ([]LocalBranch, error) {
+// ExecMerged returns the short names of the local and remote branches whose tip
+// is reachable from base, i.e. that have been merged into it.
+func (b Branch) ExecMerged(path string, base string) (map[string]bool, error) {
 	app := "git"
 
 	arg0 := "-C"
 	arg1 := path
-	arg2 := "branch"
-	arg3 := "-vv"
+	arg2 := "for-each-ref"
+	arg3 := "--format=%(refname:short)"
+	arg4 := "--merged=" + base
+	arg5 := "refs/heads"
+	arg6 := "refs/remotes"
 
-	cmd := exec.Command(app, arg0, arg1, arg2, arg3)
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 
-	if b.Verbose {
-		b.Outputter.Debug(cmd)
+	out, err := run(b.Outputter, cmd)
+	if err != nil {
+		return nil, err
 	}
 
-	out, err := cmd.Output()
+	merged := map[string]bool{}
+
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			merged[line] = true
+		}
+	}
+
+	return merged, nil
+}
+
+// localBranchFormat separates the fields of each branch with NUL, which cannot
+// appear in a ref name or author.
+var localBranchFormat = strings.Join([]string{
+	"%(HEAD)",
+	"%(refname:short)",
+	"%(objectname)",
+	"%(upstream:short)",
+	"%(upstream:track,nobracket)",
+	"%(committerdate:iso-strict)",
+	"%(authorname)",
+}, "%00")
+
+// ExecLocal returns the local branches with their upstream and last commit.
+func (b Branch) ExecLocal(path string) ([]LocalBranch, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "for-each-ref"
+	arg3 := "--format=" + localBranchFormat
+	arg4 := "refs/heads"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4)
+
+	out, err := run(b.Outputter, cmd)
 	if err != nil {
 		return nil, err
 	}
 
-	branches := b.parseBranchVVOutput(bytes.NewReader(out))
-
-	return branches, nil
+	return b.parseLocalRefsOutput(bytes.NewReader(out))
 }
 
-func (b Branch) parseBranchVVOutput(r io.Reader) []LocalBranch {
+func (b Branch) parseLocalRefsOutput(r io.Reader) ([]LocalBranch, error) {
 	branches := []LocalBranch{}
 
 	s := bufio.NewScanner(r)
@@ -116,44 +310,74 @@ func (b Branch) parseBranchVVOutput(r io.Reader) []LocalBranch {
 	for s.Scan() {
 		line := s.Text()
 
-		if b.Verbose {
-			b.Outputter.Debug(line)
-		}
+		b.Outputter.Debug(strings.ReplaceAll(line, "\x00", " "))
 
-		if line != "" {
-			branch := b.parseBranchVVLine(line)
+		if line == "" {
+			continue
+		}
 
-			branches = append(branches, branch)
+		branch, err := parseLocalRefLine(line)
+		if err != nil {
+			return nil, err
 		}
+
+		branches = append(branches, branch)
 	}
 
-	return branches
+	return branches, nil
 }
 
-func (b Branch) parseBranchVVLine(line string) LocalBranch {
-	currentBranch := line[0] == '*'
-
-	parts := strings.Split(strings.TrimSpace(line[1:]), " ")
+func parseLocalRefLine(line string) (LocalBranch, error) {
+	// Example line, with NUL between the fields:
+	//*	main	2f1c5c0e9d0c3c1b0f0d8a6c3f3b7e0e1a2b3c4d	origin/main	ahead 1, behind 2	2021-11-05T10:15:00+00:00	Kevin Lyall
+	fields := strings.Split(line, "\x00")
+	if len(fields) != 7 {
+		return LocalBranch{}, fmt.Errorf("unable to parse branch '%s'", line)
+	}
 
-	var remoteBranch string
+	date, err := parseCommitDate(fields[1], fields[5])
+	if err != nil {
+		return LocalBranch{}, err
+	}
 
-	if start := strings.Index(line, "["); start != -1 {
+	return LocalBranch{
+		LocalBranchName:  LocalBranchName(fields[1]),
+		RemoteBranchName: RemoteBranchName(fields[3]),
+		CurrentBranch:    fields[0] == "*",
+		TipSHA:           fields[2],
+		Track:            parseTrack(fields[4]),
+		LastCommitDate:   date,
+		LastCommitAuthor: fields[6],
+	}, nil
+}
 
-		end := strings.Index(line, "]")
-		remoteStatus := line[start+1 : end]
+func parseCommitDate(branch string, input string) (time.Time, error) {
+	if input == "" {
+		return time.Time{}, nil
+	}
 
-		if i := strings.Index(remoteStatus, ":"); i != -1 {
-			remoteBranch = remoteStatus[:i]
-		} else {
-			remoteBranch = remoteStatus
-		}
+	date, err := time.Parse(time.RFC3339, input)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse date of branch '%s': %w", branch, err)
 	}
 
-	branch := LocalBranch{
-		LocalBranchName:  LocalBranchName(parts[0]),
-		RemoteBranchName: RemoteBranchName(remoteBranch),
-		CurrentBranch:    currentBranch,
+	return date, nil
+}
+
+// parseTrack parses the upstream track state, e.g. "ahead 1, behind 2" or "gone".
+func parseTrack(input string) TrackState {
+	var track TrackState
+
+	for _, part := range strings.Split(input, ", ") {
+		switch {
+		case part == "gone":
+			track.Gone = true
+		case strings.HasPrefix(part, "ahead "):
+			track.Ahead, _ = strconv.Atoi(strings.TrimPrefix(part, "ahead "))
+		case strings.HasPrefix(part, "behind "):
+			track.Behind, _ = strconv.Atoi(strings.TrimPrefix(part, "behind "))
+		}
 	}
 
-	return branch
+	return track
 }
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocalRefLine(t *testing.T) {
+	// Given
+	line := strings.Join([]string{"*", "feature/[ABC-123]", "2f1c5c0e", "origin/feature/[ABC-123]",
+		"ahead 1, behind 2", "2021-11-05T10:15:00+00:00", "Kevin Lyall"}, "\x00")
+
+	// When
+	branch, err := parseLocalRefLine(line)
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, branch.LastCommitDate.Equal(time.Date(2021, 11, 5, 10, 15, 0, 0, time.UTC)))
+
+	branch.LastCommitDate = time.Time{}
+	assert.Equal(t, branch, LocalBranch{
+		LocalBranchName:  "feature/[ABC-123]",
+		RemoteBranchName: "origin/feature/[ABC-123]",
+		CurrentBranch:    true,
+		TipSHA:           "2f1c5c0e",
+		Track:            TrackState{Ahead: 1, Behind: 2},
+		LastCommitAuthor: "Kevin Lyall",
+	})
+}
+
+func TestParseTrackOfGoneUpstream(t *testing.T) {
+	// When
+	track := parseTrack("gone")
+
+	// Then
+	assert.Equal(t, track, TrackState{Gone: true})
+}
+
+func TestParseRemoteRefsOutputSkipsSymbolicRefs(t *testing.T) {
+	// Given
+	testee := Branch{Outputter: output.SStdOut{}}
+	input := "origin/HEAD\x00refs/remotes/origin/main\x00abc\x002021-11-05T10:15:00Z\x00Kevin Lyall\n" +
+		"origin/main\x00\x00abc\x002021-11-05T10:15:00Z\x00Kevin Lyall\n"
+
+	// When
+	branches, err := testee.parseRemoteRefsOutput(strings.NewReader(input))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, len(branches), 1)
+	assert.Equal(t, branches[0].RemoteBranchName, RemoteBranchName("origin/main"))
+	assert.Equal(t, branches[0].LastCommitAuthor, "Kevin Lyall")
+}
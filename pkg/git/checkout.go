@@ -0,0 +1,50 @@
+package git
+
+import (
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+)
+
+type Checkout struct {
+	Outputter output.Outputter
+}
+
+// Exec switches to the local branch, creating it to track the remote branch
+// of the same name when it does not exist.
+func (c Checkout) Exec(path string, branch LocalBranchName) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "checkout"
+	arg3 := string(branch)
+	arg4 := "--"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4)
+
+	out, err := run(c.Outputter, cmd)
+
+	c.Outputter.DebugBytes(out)
+
+	return err
+}
+
+// ExecDetach checks out the commit without a branch, leaving HEAD detached.
+func (c Checkout) ExecDetach(path string, commit string) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "checkout"
+	arg3 := "--detach"
+	arg4 := commit
+	arg5 := "--"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	out, err := run(c.Outputter, cmd)
+
+	c.Outputter.DebugBytes(out)
+
+	return err
+}
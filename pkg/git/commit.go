@@ -0,0 +1,30 @@
+package git
+
+import (
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+)
+
+type Commit struct {
+	Outputter output.Outputter
+}
+
+// Exec commits the staged changes with the message. With all set, changes to
+// tracked files are committed whether or not they are staged.
+func (c Commit) Exec(path string, message string, all bool) error {
+	app := "git"
+
+	args := []string{"-C", path, "commit", "--message", message}
+
+	if all {
+		args = append(args, "--all")
+	}
+
+	cmd := exec.Command(app, args...)
+
+	out, err := run(c.Outputter, cmd)
+
+	c.Outputter.DebugBytes(out)
+
+	return err
+}
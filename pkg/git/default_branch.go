@@ -0,0 +1,169 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"github.com/klyall/kl-cli/pkg/output"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoDefaultBranch = errors.New("unable to detect the default branch")
+
+// DefaultBranchSource is where the default branch of a repository was found.
+type DefaultBranchSource string
+
+const (
+	// FromOriginHead is the refs/remotes/origin/HEAD ref set when cloning
+	FromOriginHead DefaultBranchSource = "origin/HEAD"
+	// FromRemote is the HEAD of the origin remote, found with ls-remote
+	FromRemote DefaultBranchSource = "remote"
+	// FromManifest is the default branch given for the repository in the config file
+	FromManifest DefaultBranchSource = "manifest"
+	// FromGuess is origin/main or origin/master, whichever exists
+	FromGuess DefaultBranchSource = "guess"
+)
+
+type DetectedBranch struct {
+	Name   RemoteBranchName    `json:"name"`
+	Source DefaultBranchSource `json:"source"`
+}
+
+// DefaultBranch detects the branch of the origin remote that other branches
+// are compared with, e.g. origin/main.
+type DefaultBranch struct {
+	Outputter output.Outputter
+	// Manifest is the default branch of repositories by lower case directory
+	// name, used when origin/HEAD is not set, e.g. {"kl-cli": "develop"}
+	Manifest map[string]string
+	// QueryRemote asks the origin remote for its HEAD when origin/HEAD is not
+	// set, which needs network access
+	QueryRemote bool
+}
+
+func (d DefaultBranch) Exec(path string) (DetectedBranch, error) {
+	if branch, err := d.execSymbolicRef(path); err == nil {
+		return DetectedBranch{branch, FromOriginHead}, nil
+	}
+
+	if d.QueryRemote {
+		if branch, err := d.execLsRemote(path); err == nil {
+			return DetectedBranch{branch, FromRemote}, nil
+		}
+	}
+
+	if name, ok := d.Manifest[strings.ToLower(filepath.Base(path))]; ok && name != "" {
+		return DetectedBranch{RemoteBranchName("origin/" + strings.TrimPrefix(name, "origin/")), FromManifest}, nil
+	}
+
+	// Clones made before origin/HEAD was set, or by tools that do not set it
+	for _, branch := range []RemoteBranchName{"origin/main", "origin/master"} {
+		if d.execVerify(path, "refs/remotes/"+string(branch)) {
+			return DetectedBranch{branch, FromGuess}, nil
+		}
+	}
+
+	return DetectedBranch{}, ErrNoDefaultBranch
+}
+
+// ExecSetHead sets refs/remotes/origin/HEAD to the branch, e.g. origin/main.
+func (d DefaultBranch) ExecSetHead(path string, branch RemoteBranchName) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "remote"
+	arg3 := "set-head"
+	arg4 := "origin"
+	arg5 := strings.TrimPrefix(string(branch), "origin/")
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	out, err := run(d.Outputter, cmd)
+
+	d.Outputter.DebugBytes(out)
+
+	return err
+}
+
+func (d DefaultBranch) execSymbolicRef(path string) (RemoteBranchName, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "symbolic-ref"
+	arg3 := "--short"
+	arg4 := "refs/remotes/origin/HEAD"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4)
+
+	out, err := run(d.Outputter, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return RemoteBranchName(strings.TrimSpace(string(out))), nil
+}
+
+func (d DefaultBranch) execLsRemote(path string) (RemoteBranchName, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "ls-remote"
+	arg3 := "--symref"
+	arg4 := "origin"
+	arg5 := "HEAD"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	out, err := run(d.Outputter, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return d.parseLsRemoteOutput(bytes.NewReader(out))
+}
+
+func (d DefaultBranch) parseLsRemoteOutput(r io.Reader) (RemoteBranchName, error) {
+	// Example output:
+	//ref: refs/heads/main	HEAD
+	//2f1c5c0e9d0c3c1b0f0d8a6c3f3b7e0e1a2b3c4d	HEAD
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		line := s.Text()
+
+		d.Outputter.Debug(line)
+
+		if !strings.HasPrefix(line, "ref: refs/heads/") || !strings.HasSuffix(line, "\tHEAD") {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(line, "ref: refs/heads/"), "\tHEAD")
+
+		return RemoteBranchName("origin/" + name), nil
+	}
+
+	return "", ErrNoDefaultBranch
+}
+
+func (d DefaultBranch) execVerify(path string, ref string) bool {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "rev-parse"
+	arg3 := "--verify"
+	arg4 := "--quiet"
+	arg5 := ref
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	_, err := run(d.Outputter, cmd)
+
+	return err == nil
+}
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLsRemoteOutput(t *testing.T) {
+	// Given
+	testee := DefaultBranch{Outputter: output.SStdOut{}}
+	input := "ref: refs/heads/develop\tHEAD\n2f1c5c0e9d0c3c1b0f0d8a6c3f3b7e0e1a2b3c4d\tHEAD\n"
+
+	// When
+	branch, err := testee.parseLsRemoteOutput(strings.NewReader(input))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, branch, RemoteBranchName("origin/develop"))
+}
+
+func TestDefaultBranchFromManifest(t *testing.T) {
+	// Given a directory that is not a repository, so only the manifest can be used
+	testee := DefaultBranch{
+		Outputter: output.SStdOut{},
+		Manifest:  map[string]string{"kl-cli": "develop"},
+	}
+
+	// When
+	branch, err := testee.Exec(t.TempDir() + "/KL-CLI")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, branch, DetectedBranch{"origin/develop", FromManifest})
+}
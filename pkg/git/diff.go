@@ -0,0 +1,67 @@
+package git
+
+import (
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type Diff struct {
+	Outputter output.Outputter
+}
+
+// ExecNumstat returns the lines added and removed for each file changed in
+// the working tree or, when cached, in the index.
+func (d Diff) ExecNumstat(path string, cached bool) (map[string]DiffStat, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "--no-optional-locks"
+	arg3 := "diff"
+	arg4 := "--numstat"
+	// Renamed files are listed by their new path, as in the output of status
+	arg5 := "--no-renames"
+	// Separates files with NUL so paths are not quoted, as in the output of status
+	arg6 := "-z"
+
+	args := []string{arg0, arg1, arg2, arg3, arg4, arg5, arg6}
+	if cached {
+		args = append(args, "--cached")
+	}
+
+	cmd := exec.Command(app, args...)
+
+	out, err := run(d.Outputter, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.parseNumstatOutput(string(out)), nil
+}
+
+func (d Diff) parseNumstatOutput(out string) map[string]DiffStat {
+	// Example NUL separated entries, binary files have no line counts:
+	//3	1	cmd/git.go
+	//-	-	logo.png
+	stats := map[string]DiffStat{}
+
+	for _, entry := range strings.Split(out, "\x00") {
+		parts := strings.SplitN(entry, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		added, addedErr := strconv.Atoi(parts[0])
+		removed, removedErr := strconv.Atoi(parts[1])
+
+		stats[parts[2]] = DiffStat{
+			Added:   added,
+			Removed: removed,
+			Binary:  addedErr != nil && removedErr != nil,
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNumstatOutput(t *testing.T) {
+	// Given
+	testee := Diff{Outputter: output.SStdOut{}}
+	input := "3\t1\tcmd/git.go\x00-\t-\tlogo.png\x002\t0\tmy file.txt\x00"
+
+	// When
+	stats := testee.parseNumstatOutput(input)
+
+	// Then
+	assert.Equal(t, stats["cmd/git.go"], DiffStat{Added: 3, Removed: 1})
+	assert.Equal(t, stats["logo.png"], DiffStat{Binary: true})
+	assert.Equal(t, stats["my file.txt"], DiffStat{Added: 2})
+}
+
+func TestParseFileEntryOfRenamedFile(t *testing.T) {
+	// When
+	fs := parseFileEntry("R  new.go", "old.go")
+
+	// Then
+	assert.Equal(t, fs.Path, "new.go")
+	assert.Equal(t, fs.OriginalPath, "old.go")
+	assert.Equal(t, fs.Text, "R  old.go -> new.go")
+	assert.Equal(t, fs.Staged, true)
+	assert.Equal(t, fs.Unstaged, false)
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+	"time"
+)
+
+// run executes a git command, writing the command at debug level and its
+// duration and exit code at trace level.
+func run(outputter output.Outputter, cmd *exec.Cmd) ([]byte, error) {
+	outputter.Debug(cmd)
+
+	start := time.Now()
+	out, err := cmd.Output()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	outputter.Trace(fmt.Sprintf("%s: exit code %d in %s", cmd, exitCode(err), elapsed))
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		outputter.DebugBytes(exitErr.Stderr)
+	}
+
+	return out, err
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
@@ -6,7 +6,6 @@ import (
 )
 
 type Fetch struct {
-	Verbose   bool
 	Outputter output.Outputter
 }
 
@@ -20,15 +19,9 @@ func (f Fetch) ExecWithPurge(path string) error {
 
 	cmd := exec.Command(app, arg0, arg1, arg2, arg3)
 
-	if f.Verbose {
-		f.Outputter.Debug(cmd)
-	}
+	out, err := run(f.Outputter, cmd)
 
-	out, err := cmd.Output()
-
-	if f.Verbose {
-		f.Outputter.DebugBytes(out)
-	}
+	f.Outputter.DebugBytes(out)
 
 	return err
 }
@@ -42,15 +35,9 @@ func (f Fetch) Exec(path string) error {
 
 	cmd := exec.Command(app, arg0, arg1, arg2)
 
-	if f.Verbose {
-		f.Outputter.Debug(cmd)
-	}
-
-	out, err := cmd.Output()
+	out, err := run(f.Outputter, cmd)
 
-	if f.Verbose {
-		f.Outputter.DebugBytes(out)
-	}
+	f.Outputter.DebugBytes(out)
 
 	return err
 }
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/output"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type Grep struct {
+	Outputter output.Outputter
+}
+
+// GrepOptions is what to search the tracked files of a repository for.
+type GrepOptions struct {
+	Pattern string
+	// ExtendedRegexp matches the pattern as an extended rather than a basic
+	// regular expression
+	ExtendedRegexp bool
+	// FixedStrings matches the pattern literally rather than as a regular expression
+	FixedStrings bool
+	IgnoreCase   bool
+	// Pathspecs limit the files searched, e.g. "*.go" or ":!vendor"
+	Pathspecs []string
+}
+
+// Exec returns the lines of the tracked files that match the pattern, skipping
+// binary files. No matches is not an error.
+func (g Grep) Exec(path string, options GrepOptions) ([]GrepMatch, error) {
+	app := "git"
+
+	args := []string{"-C", path, "grep", "--line-number", "--null", "--no-color", "-I"}
+
+	if options.ExtendedRegexp {
+		args = append(args, "--extended-regexp")
+	}
+
+	if options.FixedStrings {
+		args = append(args, "--fixed-strings")
+	}
+
+	if options.IgnoreCase {
+		args = append(args, "--ignore-case")
+	}
+
+	args = append(args, "-e", options.Pattern, "--")
+	args = append(args, options.Pathspecs...)
+
+	cmd := exec.Command(app, args...)
+
+	out, err := run(g.Outputter, cmd)
+
+	// git grep exits with 1 when nothing matched
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() == 1 && len(exitErr.Stderr) == 0 {
+			return nil, nil
+		}
+
+		// Describe the problem, e.g. an invalid regular expression
+		if message := strings.TrimSpace(string(exitErr.Stderr)); message != "" {
+			return nil, errors.New(message)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return g.parseGrepOutput(bytes.NewReader(out))
+}
+
+func (g Grep) parseGrepOutput(r io.Reader) ([]GrepMatch, error) {
+	// Example line, with NUL after the path and line number:
+	//cmd/git_grep.go	42		gitGrep := git.Grep{
+	var matches []GrepMatch
+
+	// Read without a limit on the length of a line, as a match may be in a
+	// minified file
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		g.Outputter.Debug(strings.ReplaceAll(line, "\x00", " "))
+
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unable to parse match '%s'", line)
+		}
+
+		number, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse line number of match '%s': %w", line, err)
+		}
+
+		matches = append(matches, GrepMatch{
+			Path: fields[0],
+			Line: number,
+			Text: fields[2],
+		})
+	}
+
+	return matches, nil
+}
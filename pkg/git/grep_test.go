@@ -0,0 +1,43 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGrepOutput(t *testing.T) {
+	// Given
+	testee := Grep{Outputter: output.SStdOut{}}
+	input := "cmd/git_grep.go\x0042\x00\tgitGrep := git.Grep{\n" +
+		"README.md\x007\x00* git grep: a:b\n"
+
+	// When
+	matches, err := testee.parseGrepOutput(strings.NewReader(input))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, len(matches), 2)
+	assert.Equal(t, matches[0], GrepMatch{Path: "cmd/git_grep.go", Line: 42, Text: "\tgitGrep := git.Grep{"})
+	assert.Equal(t, matches[1].Text, "* git grep: a:b")
+}
+
+func TestParseGrepOutputLongLine(t *testing.T) {
+	// Given
+	testee := Grep{Outputter: output.SStdOut{}}
+	long := strings.Repeat("x", 2*1024*1024)
+	input := "dist/bundle.min.js\x001\x00" + long + "\n" +
+		"README.md\x007\x00no trailing newline"
+
+	// When
+	matches, err := testee.parseGrepOutput(strings.NewReader(input))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, len(matches), 2)
+	assert.Equal(t, matches[0].Path, "dist/bundle.min.js")
+	assert.Equal(t, len(matches[0].Text), len(long))
+	assert.Equal(t, matches[1], GrepMatch{Path: "README.md", Line: 7, Text: "no trailing newline"})
+}
@@ -0,0 +1,128 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/output"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type Log struct {
+	Outputter output.Outputter
+}
+
+// LogFilter limits the commits returned by Log. Empty fields are not used.
+type LogFilter struct {
+	// Since is any date git understands, e.g. "2 days ago" or "2021-11-01"
+	Since  string
+	Author string
+	// Grep matches the commit message, ignoring case
+	Grep string
+	// Revision is the branch, or range such as HEAD..@{upstream}, to list the
+	// commits of, defaulting to HEAD
+	Revision string
+	// Files counts the files changed by each commit
+	Files bool
+}
+
+// ShortSHA returns the abbreviated commit hash shown by git.
+func (c LogEntry) ShortSHA() string {
+	if len(c.SHA) > 7 {
+		return c.SHA[:7]
+	}
+	return c.SHA
+}
+
+// commitFormat separates the fields of each commit with NUL, which cannot
+// appear in a commit message.
+var commitFormat = strings.Join([]string{"%H", "%aI", "%an", "%ae", "%s"}, "%x00")
+
+func (l Log) Exec(path string, filter LogFilter) ([]LogEntry, error) {
+	app := "git"
+
+	args := []string{"-C", path, "log", "--format=" + commitFormat}
+
+	if filter.Since != "" {
+		args = append(args, "--since="+filter.Since)
+	}
+
+	if filter.Author != "" {
+		args = append(args, "--author="+filter.Author)
+	}
+
+	if filter.Grep != "" {
+		args = append(args, "--grep="+filter.Grep, "--regexp-ignore-case")
+	}
+
+	if filter.Files {
+		args = append(args, "--name-only")
+	}
+
+	if filter.Revision != "" {
+		args = append(args, filter.Revision)
+	}
+
+	args = append(args, "--")
+
+	cmd := exec.Command(app, args...)
+
+	out, err := run(l.Outputter, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.parseLogOutput(bytes.NewReader(out))
+}
+
+func (l Log) parseLogOutput(r io.Reader) ([]LogEntry, error) {
+	// Example line, with NUL between the fields:
+	//2f1c5c0e9d0c3c1b0f0d8a6c3f3b7e0e1a2b3c4d	2021-11-05T10:15:00+00:00	Kevin Lyall	kevin@example.com	Add log command
+	//
+	//cmd/git_log.go
+	//pkg/git/log.go
+	// The names of the files changed follow each commit when they are counted.
+	var commits []LogEntry
+
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		line := s.Text()
+
+		l.Outputter.Debug(strings.ReplaceAll(line, "\x00", " "))
+
+		if line == "" {
+			continue
+		}
+
+		if !strings.Contains(line, "\x00") {
+			if len(commits) > 0 {
+				commits[len(commits)-1].FilesChanged++
+			}
+			continue
+		}
+
+		fields := strings.SplitN(line, "\x00", 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unable to parse commit '%s'", line)
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse date of commit '%s': %w", fields[0], err)
+		}
+
+		commits = append(commits, LogEntry{
+			SHA:     fields[0],
+			Date:    date,
+			Author:  fields[2],
+			Email:   fields[3],
+			Subject: fields[4],
+		})
+	}
+
+	return commits, nil
+}
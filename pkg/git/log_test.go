@@ -0,0 +1,44 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogOutput(t *testing.T) {
+	// Given
+	testee := Log{Outputter: output.SStdOut{}}
+	input := "2f1c5c0e9d0c3c1b0f0d8a6c3f3b7e0e1a2b3c4d\x002021-11-05T10:15:00+00:00\x00Kevin Lyall\x00kevin@example.com\x00[ABC-123] Add log command\n"
+
+	// When
+	commits, err := testee.parseLogOutput(strings.NewReader(input))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, len(commits), 1)
+	assert.Equal(t, commits[0].ShortSHA(), "2f1c5c0")
+	assert.Equal(t, commits[0].Author, "Kevin Lyall")
+	assert.Equal(t, commits[0].Subject, "[ABC-123] Add log command")
+}
+
+func TestParseLogOutputWithFiles(t *testing.T) {
+	// Given
+	testee := Log{Outputter: output.SStdOut{}}
+	input := "2f1c5c0e9d0c3c1b0f0d8a6c3f3b7e0e1a2b3c4d\x002021-11-05T10:15:00+00:00\x00Kevin Lyall\x00kevin@example.com\x00Add log command\n" +
+		"\n" +
+		"cmd/git_log.go\n" +
+		"pkg/git/log.go\n" +
+		"9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b\x002021-11-04T09:00:00+00:00\x00Kevin Lyall\x00kevin@example.com\x00Merge branch 'main'\n"
+
+	// When
+	commits, err := testee.parseLogOutput(strings.NewReader(input))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, len(commits), 2)
+	assert.Equal(t, commits[0].FilesChanged, 2)
+	assert.Equal(t, commits[1].FilesChanged, 0)
+}
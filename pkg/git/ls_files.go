@@ -0,0 +1,112 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/klyall/kl-cli/pkg/output"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type LsFiles struct {
+	Outputter output.Outputter
+}
+
+// ExecIgnoredDirectories returns the directories, relative to path, that are
+// wholly ignored by git, e.g. build output and dependency folders.
+func (l LsFiles) ExecIgnoredDirectories(path string) ([]string, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "ls-files"
+	arg3 := "--others"
+	arg4 := "--ignored"
+	arg5 := "--exclude-standard"
+	arg6 := "--directory"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+
+	out, err := run(l.Outputter, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.parseDirectoriesOutput(bytes.NewReader(out)), nil
+}
+
+func (l LsFiles) parseDirectoriesOutput(r io.Reader) []string {
+	var directories []string
+
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		line := s.Text()
+
+		l.Outputter.Debug(line)
+
+		if strings.HasSuffix(line, "/") {
+			directories = append(directories, filepath.FromSlash(strings.TrimSuffix(line, "/")))
+		}
+	}
+
+	return directories
+}
+
+// ExecTracked returns the paths, relative to path, of the files tracked by git.
+func (l LsFiles) ExecTracked(path string) ([]string, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "ls-files"
+	arg3 := "-z"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3)
+
+	out, err := run(l.Outputter, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.parseTrackedOutput(out), nil
+}
+
+func (l LsFiles) parseTrackedOutput(out []byte) []string {
+	// Paths are separated by NUL so they are not quoted
+	var files []string
+
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			l.Outputter.Debug(file)
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// ExecIsTracked reports whether the file, relative to path, is tracked by git.
+func (l LsFiles) ExecIsTracked(path string, file string) (bool, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	// Stops the file name being read as a pattern, e.g. when it contains '*'
+	arg2 := "--literal-pathspecs"
+	arg3 := "ls-files"
+	arg4 := "-z"
+	arg5 := "--"
+	arg6 := file
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+
+	out, err := run(l.Outputter, cmd)
+	if err != nil {
+		return false, err
+	}
+
+	return len(l.parseTrackedOutput(out)) > 0, nil
+}
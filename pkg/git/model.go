@@ -1,39 +1,111 @@
 package git
 
+import "time"
+
 type LocalBranchName string
 type RemoteBranchName string
 
 type LocalBranch struct {
-	LocalBranchName  LocalBranchName
-	RemoteBranchName RemoteBranchName
-	CurrentBranch    bool
+	LocalBranchName  LocalBranchName  `json:"localBranchName"`
+	RemoteBranchName RemoteBranchName `json:"remoteBranchName,omitempty"`
+	CurrentBranch    bool             `json:"currentBranch"`
+	TipSHA           string           `json:"tipSha"`
+	Track            TrackState       `json:"track"`
+	LastCommitDate   time.Time        `json:"lastCommitDate"`
+	LastCommitAuthor string           `json:"lastCommitAuthor"`
+}
+
+type RemoteBranch struct {
+	RemoteBranchName RemoteBranchName `json:"remoteBranchName"`
+	TipSHA           string           `json:"tipSha"`
+	LastCommitDate   time.Time        `json:"lastCommitDate"`
+	LastCommitAuthor string           `json:"lastCommitAuthor"`
+}
+
+// TrackState is how a local branch compares with its upstream branch. Gone is
+// set when the upstream branch has been deleted from the remote.
+type TrackState struct {
+	Ahead  int  `json:"ahead"`
+	Behind int  `json:"behind"`
+	Gone   bool `json:"gone"`
 }
 
 type RepositoryStatus struct {
-	Versioned     bool
-	VersionNumber string
-	LocalBranch   string
-	RemoteBranch  string
-	LocalStatus   StatusMessage
-	RemoteStatus  StatusMessage
-	CommitsAhead  int
-	CommitsBehind int
-	Staged        int
-	Unstaged      int
-	Untracked     int
-	Ignored       int
-	FilesStatus   []FileStatus
+	Versioned     bool   `json:"versioned"`
+	VersionNumber string `json:"versionNumber,omitempty"`
+	LocalBranch   string `json:"localBranch,omitempty"`
+	RemoteBranch  string `json:"remoteBranch,omitempty"`
+	// UpstreamGone is set when the remote branch has been deleted from the remote
+	UpstreamGone  bool          `json:"upstreamGone,omitempty"`
+	LocalStatus   StatusMessage `json:"localStatus"`
+	RemoteStatus  StatusMessage `json:"remoteStatus"`
+	CommitsAhead  int           `json:"commitsAhead"`
+	CommitsBehind int           `json:"commitsBehind"`
+	DefaultBranch string        `json:"defaultBranch,omitempty"`
+	// BaseBranch is the branch, usually the default branch, that the commits
+	// ahead and behind base are counted from. Empty when it is unknown.
+	BaseBranch        string       `json:"baseBranch,omitempty"`
+	CommitsAheadBase  int          `json:"commitsAheadBase"`
+	CommitsBehindBase int          `json:"commitsBehindBase"`
+	Staged            int          `json:"staged"`
+	Unstaged          int          `json:"unstaged"`
+	Untracked         int          `json:"untracked"`
+	Ignored           int          `json:"ignored"`
+	FilesStatus       []FileStatus `json:"filesStatus,omitempty"`
 }
 
 type FileStatus struct {
-	Text      string
-	Staged    bool
-	Unstaged  bool
-	Untracked bool
-	Ignored   bool
+	Text string `json:"text"`
+	Path string `json:"path"`
+	// OriginalPath of a renamed or copied file
+	OriginalPath string `json:"originalPath,omitempty"`
+	Staged       bool   `json:"staged"`
+	Unstaged     bool   `json:"unstaged"`
+	Untracked    bool   `json:"untracked"`
+	Ignored      bool   `json:"ignored"`
+	// Lines changed are only set when the diffstat is requested
+	StagedDiff   *DiffStat `json:"stagedDiff,omitempty"`
+	UnstagedDiff *DiffStat `json:"unstagedDiff,omitempty"`
+}
+
+type DiffStat struct {
+	Added   int  `json:"added"`
+	Removed int  `json:"removed"`
+	Binary  bool `json:"binary,omitempty"`
+}
+
+// LogEntry is a commit listed by git log.
+type LogEntry struct {
+	SHA     string    `json:"sha"`
+	Date    time.Time `json:"date"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Subject string    `json:"subject"`
+	// FilesChanged is only set when the files are counted
+	FilesChanged int `json:"filesChanged,omitempty"`
+}
+
+// GrepMatch is a line of a file that matched a pattern. The path is relative
+// to the root of the repository.
+type GrepMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
 }
 
 type RepositoryRemote struct {
-	Fetch string
-	Push  string
+	Fetch    string    `json:"fetch"`
+	Push     string    `json:"push"`
+	FetchURL RemoteURL `json:"fetchUrl"`
+}
+
+type PurgedBranch struct {
+	LocalBranchName LocalBranchName `json:"localBranchName"`
+	Outcome         PurgeOutcome    `json:"outcome"`
+	Err             error           `json:"-"`
+}
+
+type RepositoryPurge struct {
+	HasRemote bool           `json:"hasRemote"`
+	Branches  []PurgedBranch `json:"branches"`
 }
@@ -6,7 +6,6 @@ import (
 )
 
 type Pull struct {
-	Verbose   bool
 	Outputter output.Outputter
 }
 
@@ -19,15 +18,28 @@ func (p Pull) Exec(path string) error {
 
 	cmd := exec.Command(app, arg0, arg1, arg2)
 
-	if p.Verbose {
-		p.Outputter.Debug(cmd)
-	}
+	out, err := run(p.Outputter, cmd)
 
-	out, err := cmd.Output()
+	p.Outputter.DebugBytes(out)
 
-	if p.Verbose {
-		p.Outputter.DebugBytes(out)
-	}
+	return err
+}
+
+// ExecFastForward pulls the current branch only when it can be fast-forwarded,
+// so local commits are never merged.
+func (p Pull) ExecFastForward(path string) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "pull"
+	arg3 := "--ff-only"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3)
+
+	out, err := run(p.Outputter, cmd)
+
+	p.Outputter.DebugBytes(out)
 
 	return err
 }
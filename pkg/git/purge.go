@@ -0,0 +1,97 @@
+package git
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/output"
+)
+
+type PurgeOutcome int
+
+const (
+	BranchDeleted PurgeOutcome = iota
+	BranchDeleteFailed
+	BranchIsCurrent
+	BranchWouldBeDeleted
+)
+
+var purgeOutcomeNames = map[PurgeOutcome]string{
+	BranchDeleted:        "deleted",
+	BranchDeleteFailed:   "deleteFailed",
+	BranchIsCurrent:      "current",
+	BranchWouldBeDeleted: "wouldBeDeleted",
+}
+
+func (o PurgeOutcome) MarshalText() ([]byte, error) {
+	return []byte(purgeOutcomeNames[o]), nil
+}
+
+// Purge removes all local branches that no longer have a valid remote branch.
+type Purge struct {
+	Outputter output.Outputter
+	DryRun    bool
+}
+
+func (p Purge) Exec(path string) (RepositoryPurge, error) {
+	gitFetch := Fetch{
+		Outputter: p.Outputter,
+	}
+
+	gitBranch := Branch{
+		Outputter: p.Outputter,
+	}
+
+	if err := gitFetch.ExecWithPurge(path); err != nil {
+		return RepositoryPurge{}, fmt.Errorf("unable to fetch: %w", err)
+	}
+
+	remoteBranches, err := gitBranch.ExecRemote(path)
+	if err != nil {
+		return RepositoryPurge{}, fmt.Errorf("unable to retrieve remote branches: %w", err)
+	}
+
+	localBranches, err := gitBranch.ExecLocal(path)
+	if err != nil {
+		return RepositoryPurge{}, fmt.Errorf("unable to retrieve branches: %w", err)
+	}
+
+	purge := RepositoryPurge{
+		HasRemote: len(remoteBranches) > 0,
+	}
+
+	for _, lb := range localBranches {
+		if lb.RemoteBranchName == "" || containsRemoteBranch(remoteBranches, lb.RemoteBranchName) {
+			continue
+		}
+
+		purged := PurgedBranch{
+			LocalBranchName: lb.LocalBranchName,
+		}
+
+		switch {
+		case lb.CurrentBranch:
+			purged.Outcome = BranchIsCurrent
+		case p.DryRun:
+			purged.Outcome = BranchWouldBeDeleted
+		default:
+			if err := gitBranch.ExecDelete(path, lb.LocalBranchName); err != nil {
+				purged.Outcome = BranchDeleteFailed
+				purged.Err = err
+			} else {
+				purged.Outcome = BranchDeleted
+			}
+		}
+
+		purge.Branches = append(purge.Branches, purged)
+	}
+
+	return purge, nil
+}
+
+func containsRemoteBranch(r []RemoteBranchName, branch RemoteBranchName) bool {
+	for _, b := range r {
+		if b == branch {
+			return true
+		}
+	}
+	return false
+}
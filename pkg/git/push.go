@@ -0,0 +1,66 @@
+package git
+
+import (
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+)
+
+type Push struct {
+	Outputter output.Outputter
+}
+
+func (p Push) Exec(path string) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "push"
+
+	cmd := exec.Command(app, arg0, arg1, arg2)
+
+	out, err := run(p.Outputter, cmd)
+
+	p.Outputter.DebugBytes(out)
+
+	return err
+}
+
+// ExecSetUpstream pushes the branch to origin and sets it as the upstream.
+func (p Push) ExecSetUpstream(path string, branch LocalBranchName) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "push"
+	arg3 := "--set-upstream"
+	arg4 := "origin"
+	arg5 := string(branch)
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	out, err := run(p.Outputter, cmd)
+
+	p.Outputter.DebugBytes(out)
+
+	return err
+}
+
+// ExecDelete deletes the branch from origin.
+func (p Push) ExecDelete(path string, branch LocalBranchName) error {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "push"
+	arg3 := "--delete"
+	arg4 := "origin"
+	arg5 := string(branch)
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+
+	out, err := run(p.Outputter, cmd)
+
+	p.Outputter.DebugBytes(out)
+
+	return err
+}
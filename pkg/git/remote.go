@@ -10,7 +10,6 @@ import (
 )
 
 type Remote struct {
-	Verbose   bool
 	Outputter output.Outputter
 }
 
@@ -24,11 +23,7 @@ func (r Remote) Exec(path string) (RepositoryRemote, error) {
 
 	cmd := exec.Command(app, arg0, arg1, arg2, arg3)
 
-	if r.Verbose {
-		r.Outputter.Debug(cmd)
-	}
-
-	out, err := cmd.Output()
+	out, err := run(r.Outputter, cmd)
 	if err != nil {
 		return RepositoryRemote{}, err
 	}
@@ -45,9 +40,13 @@ func (r Remote) parseGitRemoteOutput(reader io.Reader) RepositoryRemote {
 	s.Scan()
 	push := r.parseGitRemoteLine(s.Text())
 
+	// An unparseable URL is still reported through Fetch, so the error is not needed here
+	fetchURL, _ := ParseRemoteURL(fetch)
+
 	return RepositoryRemote{
-		Fetch: fetch,
-		Push:  push,
+		Fetch:    fetch,
+		Push:     push,
+		FetchURL: fetchURL,
 	}
 }
 
@@ -56,9 +55,7 @@ func (r Remote) parseGitRemoteLine(line string) string {
 		return ""
 	}
 
-	if r.Verbose {
-		r.Outputter.Debug(line)
-	}
+	r.Outputter.Debug(line)
 
 	s := bufio.NewScanner(strings.NewReader(line))
 	s.Split(bufio.ScanWords)
@@ -0,0 +1,47 @@
+package git
+
+import (
+	"fmt"
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+	"strings"
+)
+
+type RevList struct {
+	Outputter output.Outputter
+}
+
+// ExecAheadBehind returns the number of commits on HEAD that are not on base,
+// and on base that are not on HEAD.
+func (r RevList) ExecAheadBehind(path string, base string) (ahead int, behind int, err error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "rev-list"
+	arg3 := "--left-right"
+	arg4 := "--count"
+	arg5 := "HEAD..." + base
+	arg6 := "--"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+
+	out, err := run(r.Outputter, cmd)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return parseLeftRightCount(string(out))
+}
+
+func parseLeftRightCount(input string) (int, int, error) {
+	// Example output, commits only on the left then only on the right:
+	//1	18
+	var left, right int
+
+	if _, err := fmt.Sscanf(strings.TrimSpace(input), "%d\t%d", &left, &right); err != nil {
+		return 0, 0, fmt.Errorf("unable to parse rev-list count '%s': %w", strings.TrimSpace(input), err)
+	}
+
+	return left, right, nil
+}
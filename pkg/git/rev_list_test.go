@@ -0,0 +1,25 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLeftRightCount(t *testing.T) {
+	// When
+	ahead, behind, err := parseLeftRightCount("1\t18\n")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, ahead, 1)
+	assert.Equal(t, behind, 18)
+}
+
+func TestParseLeftRightCountOfUnexpectedOutput(t *testing.T) {
+	// When
+	_, _, err := parseLeftRightCount("")
+
+	// Then
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,35 @@
+package git
+
+import (
+	"github.com/klyall/kl-cli/pkg/output"
+	"os/exec"
+	"strings"
+)
+
+type RevParse struct {
+	Outputter output.Outputter
+}
+
+// ExecCommit returns the full SHA of the commit that ref, e.g. HEAD, names.
+func (r RevParse) ExecCommit(path string, ref string) (string, error) {
+	app := "git"
+
+	arg0 := "-C"
+	arg1 := path
+	arg2 := "rev-parse"
+	arg3 := "--verify"
+	arg4 := ref + "^{commit}"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4)
+
+	out, err := run(r.Outputter, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	sha := strings.TrimSpace(string(out))
+
+	r.Outputter.Debug(sha)
+
+	return sha, nil
+}
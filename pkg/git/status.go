@@ -2,10 +2,9 @@ package git
 
 import (
 	"bufio"
-	"bytes"
+	"encoding/json"
 	"github.com/gookit/color"
 	"github.com/klyall/kl-cli/pkg/output"
-	"io"
 	"os"
 	"os/exec"
 	"regexp"
@@ -13,22 +12,53 @@ import (
 	"strings"
 )
 
+// StatusMessage refers to its colour in the palette so it follows any theme
+// loaded from the config file.
 type StatusMessage struct {
-	Color   color.Color
+	Color   *color.Color
 	Message string
 }
 
-var CommittedChanges = StatusMessage{output.WarnColor, "Changes to push"}
-var NoChanges = StatusMessage{output.SuccessColor, "Up to date"}
-var NotVersioned = StatusMessage{output.ErrorColor, "Not versioned"}
-var RemoteChanges = StatusMessage{output.WarnColor, "Changes to pull"}
-var UncommittedChanges = StatusMessage{output.WarnColor, "Changes to commit"}
-var UntrackedChanges = StatusMessage{output.WarnColor, "Untracked changes"}
+// MarshalJSON writes only the message, as the colour is a presentation detail
+func (m StatusMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Message)
+}
+
+// String returns the message, e.g. for use in --format templates
+func (m StatusMessage) String() string {
+	return m.Message
+}
+
+var CommittedChanges = StatusMessage{&output.WarnColor, "Changes to push"}
+var NoChanges = StatusMessage{&output.SuccessColor, "Up to date"}
+var NotVersioned = StatusMessage{&output.ErrorColor, "Not versioned"}
+var RemoteChanges = StatusMessage{&output.WarnColor, "Changes to pull"}
+var UncommittedChanges = StatusMessage{&output.WarnColor, "Changes to commit"}
+var UntrackedChanges = StatusMessage{&output.WarnColor, "Untracked changes"}
 
 type Status struct {
-	Verbose   bool
 	Outputter output.Outputter
 	Strict    bool
+	// DiffStat adds the lines added and removed to the status of each changed file
+	DiffStat bool
+	// Divergence detects the default branch and counts the commits ahead and
+	// behind Base. It is off by default as it runs several more git commands.
+	Divergence bool
+	// Base is the ref to count commits ahead and behind from, in addition to
+	// the upstream. The default branch is used when empty.
+	Base string
+	// Manifest is the default branch of repositories by lower case directory
+	// name, used when it cannot be detected
+	Manifest map[string]string
+}
+
+// Files returns the status of each changed file, as shown by 'git status --short'
+func (r RepositoryStatus) Files() []string {
+	var files []string
+	for _, f := range r.FilesStatus {
+		files = append(files, f.Text)
+	}
+	return files
 }
 
 func (s Status) Exec(path string) (RepositoryStatus, error) {
@@ -36,40 +66,122 @@ func (s Status) Exec(path string) (RepositoryStatus, error) {
 
 	arg0 := "-C"
 	arg1 := path
-	arg2 := "status"
-	arg3 := "-s"
-	arg4 := "-b"
-	arg5 := "--porcelain"
+	// Stops status refreshing the index, which would otherwise be seen as a change when watching
+	arg2 := "--no-optional-locks"
+	arg3 := "status"
+	arg4 := "-s"
+	arg5 := "-b"
+	arg6 := "--porcelain"
+	// Separates entries with NUL so paths are not quoted
+	arg7 := "-z"
+
+	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+
+	out, err := run(s.Outputter, cmd)
+	if err != nil {
+		return RepositoryStatus{}, err
+	}
 
-	cmd := exec.Command(app, arg0, arg1, arg2, arg3, arg4, arg5)
+	status := s.parseGitStatusOutput(string(out))
 
-	if s.Verbose {
-		s.Outputter.Debug(cmd)
+	if s.Divergence {
+		s.addDefaultBranch(path, &status)
+		s.addBaseDivergence(path, &status)
 	}
 
-	out, err := cmd.Output()
+	if s.DiffStat && status.Staged+status.Unstaged > 0 {
+		if err := s.addDiffStats(path, status.FilesStatus); err != nil {
+			return RepositoryStatus{}, err
+		}
+	}
+
+	return status, nil
+}
+
+// addDefaultBranch detects the default branch without asking the remote, so
+// status stays quick and works offline.
+func (s Status) addDefaultBranch(path string, status *RepositoryStatus) {
+	branch, err := DefaultBranch{Outputter: s.Outputter, Manifest: s.Manifest}.Exec(path)
 	if err != nil {
-		return RepositoryStatus{}, err
+		s.Outputter.Debug(err)
+		return
+	}
+
+	status.DefaultBranch = string(branch.Name)
+}
+
+// addBaseDivergence counts the commits ahead and behind the base branch. The
+// base is left empty when it does not exist in the repository, e.g. one with
+// no remote, as this is not a failure of the status.
+func (s Status) addBaseDivergence(path string, status *RepositoryStatus) {
+	base := s.Base
+	if base == "" {
+		base = status.DefaultBranch
+	}
+
+	if base == "" {
+		return
+	}
+
+	ahead, behind, err := RevList{Outputter: s.Outputter}.ExecAheadBehind(path, base)
+	if err != nil {
+		s.Outputter.Debug(err)
+		return
+	}
+
+	status.BaseBranch = base
+	status.CommitsAheadBase = ahead
+	status.CommitsBehindBase = behind
+}
+
+func (s Status) addDiffStats(path string, fileStatuses []FileStatus) error {
+	diff := Diff{Outputter: s.Outputter}
+
+	staged, err := diff.ExecNumstat(path, true)
+	if err != nil {
+		return err
+	}
+
+	unstaged, err := diff.ExecNumstat(path, false)
+	if err != nil {
+		return err
 	}
 
-	return s.parseGitStatusOutput(bytes.NewReader(out)), nil
+	for i := range fileStatuses {
+		fs := &fileStatuses[i]
+
+		if stat, ok := staged[fs.Path]; ok && fs.Staged {
+			fs.StagedDiff = &stat
+		}
+
+		if stat, ok := unstaged[fs.Path]; ok && fs.Unstaged {
+			fs.UnstagedDiff = &stat
+		}
+	}
+
+	return nil
 }
 
-func (s Status) parseGitStatusOutput(r io.Reader) RepositoryStatus {
-	scanner := bufio.NewScanner(r)
+// parseGitStatusOutput reads the NUL separated entries of 'git status -b
+// --porcelain -z', starting with the branch.
+func (s Status) parseGitStatusOutput(out string) RepositoryStatus {
+	var entries []string
+	for _, entry := range strings.Split(out, "\x00") {
+		// Skip any empty entry
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
 
 	var localBranch, remoteBranch string
 	var ahead, behind int
+	var gone bool
 
 	//Extract branch name
-	for scanner.Scan() {
-		//Skip any empty line
-		if len(scanner.Text()) < 1 {
-			continue
-		}
-
-		localBranch, remoteBranch, ahead, behind = s.parseBranchLine(scanner.Text())
-		break
+	if len(entries) > 0 && strings.HasPrefix(entries[0], "##") {
+		localBranch, remoteBranch, ahead, behind = s.parseBranchLine(entries[0])
+		gone = strings.HasSuffix(entries[0], " [gone]")
+		entries = entries[1:]
 	}
 
 	var remoteStatus StatusMessage
@@ -84,12 +196,19 @@ func (s Status) parseGitStatusOutput(r io.Reader) RepositoryStatus {
 	}
 
 	var statuses []FileStatus
-	for scanner.Scan() {
-		if len(scanner.Text()) < 1 {
-			continue
+	for i := 0; i < len(entries); i++ {
+		var originalPath string
+
+		// Renamed and copied files are followed by an entry with their original path
+		if renamedOrCopied(entries[i]) && i+1 < len(entries) {
+			originalPath = entries[i+1]
 		}
 
-		statuses = append(statuses, parseFileLine(scanner.Text()))
+		statuses = append(statuses, parseFileEntry(entries[i], originalPath))
+
+		if originalPath != "" {
+			i++
+		}
 	}
 
 	staged, unstaged, untracked, ignored := s.calculateTotals(statuses)
@@ -109,6 +228,7 @@ func (s Status) parseGitStatusOutput(r io.Reader) RepositoryStatus {
 		Versioned:     true,
 		LocalBranch:   localBranch,
 		RemoteBranch:  remoteBranch,
+		UpstreamGone:  gone,
 		LocalStatus:   localStatus,
 		RemoteStatus:  remoteStatus,
 		CommitsAhead:  ahead,
@@ -122,9 +242,7 @@ func (s Status) parseGitStatusOutput(r io.Reader) RepositoryStatus {
 }
 
 func (s Status) parseBranchLine(input string) (string, string, int, int) {
-	if s.Verbose {
-		s.Outputter.Debug(input)
-	}
+	s.Outputter.Debug(input)
 	// Example line:
 	//## develop...origin/develop [ahead 1, behind 18]
 
@@ -189,26 +307,40 @@ func (s Status) parseCommitsBehind(input string) int {
 	return i
 }
 
-func parseFileLine(input string) FileStatus {
-	staged := input[0] != ' ' && input[0] != '?' && input[0] != '!'
-	unstaged := input[1] != ' ' && input[1] != '?' && input[1] != '!'
-	untracked := strings.HasPrefix(input, "??")
-	ignored := strings.HasPrefix(input, "!!")
+func renamedOrCopied(entry string) bool {
+	return len(entry) > 2 && strings.ContainsAny(entry[:2], "RC")
+}
+
+// parseFileEntry reads an entry such as "M  cmd/git.go", whose path is not
+// quoted. The text is shown as by 'git status --short', with renamed files
+// shown as: R  old -> new
+func parseFileEntry(entry, originalPath string) FileStatus {
+	staged := entry[0] != ' ' && entry[0] != '?' && entry[0] != '!'
+	unstaged := entry[1] != ' ' && entry[1] != '?' && entry[1] != '!'
+	untracked := strings.HasPrefix(entry, "??")
+	ignored := strings.HasPrefix(entry, "!!")
+
+	path := entry[3:]
+
+	text := entry
+	if originalPath != "" {
+		text = entry[:3] + originalPath + " -> " + path
+	}
 
 	return FileStatus{
-		Text:      input,
-		Staged:    staged,
-		Unstaged:  unstaged,
-		Untracked: untracked,
-		Ignored:   ignored,
+		Text:         text,
+		Path:         path,
+		OriginalPath: originalPath,
+		Staged:       staged,
+		Unstaged:     unstaged,
+		Untracked:    untracked,
+		Ignored:      ignored,
 	}
 }
 
 func (s Status) calculateTotals(fileStatuses []FileStatus) (staged, unstaged, untracked, ignored int) {
 	for _, fs := range fileStatuses {
-		if s.Verbose {
-			s.Outputter.Debug(fs.Text)
-		}
+		s.Outputter.Debug(fs.Text)
 
 		if fs.Staged {
 			staged++
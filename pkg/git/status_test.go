@@ -0,0 +1,55 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitStatusOutput(t *testing.T) {
+	// Given
+	testee := Status{Outputter: output.SStdOut{}}
+	input := "## main...origin/main [ahead 1]\x00" +
+		" M my file.txt\x00" +
+		"R  new name.go\x00old name.go\x00" +
+		"?? ünï.txt\x00"
+
+	// When
+	status := testee.parseGitStatusOutput(input)
+
+	// Then
+	assert.Equal(t, status.LocalBranch, "main")
+	assert.Equal(t, status.RemoteBranch, "origin/main")
+	assert.Equal(t, status.CommitsAhead, 1)
+	assert.Equal(t, len(status.FilesStatus), 3)
+
+	assert.Equal(t, status.FilesStatus[0].Path, "my file.txt")
+	assert.Equal(t, status.FilesStatus[0].Text, " M my file.txt")
+	assert.Equal(t, status.FilesStatus[0].Unstaged, true)
+
+	assert.Equal(t, status.FilesStatus[1].Path, "new name.go")
+	assert.Equal(t, status.FilesStatus[1].OriginalPath, "old name.go")
+	assert.Equal(t, status.FilesStatus[1].Text, "R  old name.go -> new name.go")
+
+	assert.Equal(t, status.FilesStatus[2].Path, "ünï.txt")
+	assert.Equal(t, status.FilesStatus[2].Untracked, true)
+
+	assert.Equal(t, status.Staged, 1)
+	assert.Equal(t, status.Unstaged, 1)
+	assert.Equal(t, status.Untracked, 1)
+}
+
+func TestParseGitStatusOutputOfGoneUpstream(t *testing.T) {
+	// Given
+	testee := Status{Outputter: output.SStdOut{}}
+
+	// When
+	status := testee.parseGitStatusOutput("## feature...origin/feature [gone]\x00")
+
+	// Then
+	assert.Equal(t, status.LocalBranch, "feature")
+	assert.Equal(t, status.RemoteBranch, "origin/feature")
+	assert.Equal(t, status.UpstreamGone, true)
+	assert.Equal(t, status.CommitsAhead, 0)
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var ErrEmptyRemoteURL = errors.New("remote url is empty")
+
+// RemoteURL is a remote repository location normalised from any of the URL
+// forms git accepts, e.g. git@github.com:klyall/kl-cli.git
+type RemoteURL struct {
+	Raw    string `json:"raw"`
+	Scheme string `json:"scheme"`
+	Host   string `json:"host"`
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+}
+
+// ParseRemoteURL parses scp-style, ssh://, git://, http(s):// and file:// remote
+// URLs. Plain paths are treated as file URLs.
+func ParseRemoteURL(raw string) (RemoteURL, error) {
+	raw = strings.TrimSpace(raw)
+
+	if raw == "" {
+		return RemoteURL{}, ErrEmptyRemoteURL
+	}
+
+	switch {
+	case strings.Contains(raw, "://"):
+		return parseSchemeURL(raw)
+	case isScpLikeURL(raw):
+		return parseScpLikeURL(raw)
+	default:
+		return newRemoteURL(raw, "file", "", raw)
+	}
+}
+
+func parseSchemeURL(raw string) (RemoteURL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return RemoteURL{}, err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+
+	if scheme == "file" {
+		return newRemoteURL(raw, scheme, "", u.Path)
+	}
+
+	if u.Hostname() == "" {
+		return RemoteURL{}, fmt.Errorf("remote url has no host: %s", raw)
+	}
+
+	return newRemoteURL(raw, scheme, strings.ToLower(u.Hostname()), u.Path)
+}
+
+// isScpLikeURL reports whether raw is of the form [user@]host:path, following
+// the same rules as git: no slash before the first colon.
+func isScpLikeURL(raw string) bool {
+	colon := strings.Index(raw, ":")
+	if colon < 1 {
+		return false
+	}
+
+	slash := strings.Index(raw, "/")
+
+	return slash == -1 || colon < slash
+}
+
+func parseScpLikeURL(raw string) (RemoteURL, error) {
+	colon := strings.Index(raw, ":")
+
+	host := raw[:colon]
+	if at := strings.LastIndex(host, "@"); at != -1 {
+		host = host[at+1:]
+	}
+
+	return newRemoteURL(raw, "ssh", strings.ToLower(host), raw[colon+1:])
+}
+
+func newRemoteURL(raw, scheme, host, repositoryPath string) (RemoteURL, error) {
+	repositoryPath = strings.Trim(path.Clean("/"+repositoryPath), "/")
+	repositoryPath = strings.TrimSuffix(repositoryPath, ".git")
+
+	if repositoryPath == "" {
+		return RemoteURL{}, fmt.Errorf("remote url has no repository path: %s", raw)
+	}
+
+	owner, name := path.Split(repositoryPath)
+
+	return RemoteURL{
+		Raw:    raw,
+		Scheme: scheme,
+		Host:   host,
+		Owner:  strings.TrimSuffix(owner, "/"),
+		Name:   name,
+	}, nil
+}
+
+// Group returns the host and owner the repository belongs to, e.g. github.com/klyall
+func (u RemoteURL) Group() string {
+	if u.Host == "" {
+		return u.Owner
+	}
+
+	if u.Owner == "" {
+		return u.Host
+	}
+
+	return u.Host + "/" + u.Owner
+}
+
+// BrowserURL returns the web page for the repository, or an empty string for
+// remotes that are not hosted on a server, e.g. file:// remotes.
+func (u RemoteURL) BrowserURL() string {
+	if u.Host == "" {
+		return ""
+	}
+
+	return "https://" + u.Group() + "/" + u.Name
+}
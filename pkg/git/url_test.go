@@ -0,0 +1,51 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		raw        string
+		host       string
+		owner      string
+		name       string
+		browserURL string
+	}{
+		{"git@github.com:klyall/kl-cli.git", "github.com", "klyall", "kl-cli", "https://github.com/klyall/kl-cli"},
+		{"github.com:klyall/kl-cli", "github.com", "klyall", "kl-cli", "https://github.com/klyall/kl-cli"},
+		{"ssh://git@github.com:22/klyall/kl-cli.git", "github.com", "klyall", "kl-cli", "https://github.com/klyall/kl-cli"},
+		{"https://github.com/klyall/kl-cli.git", "github.com", "klyall", "kl-cli", "https://github.com/klyall/kl-cli"},
+		{"https://user@GitLab.com/group/sub/kl-cli/", "gitlab.com", "group/sub", "kl-cli", "https://gitlab.com/group/sub/kl-cli"},
+		{"file:///srv/git/klyall/kl-cli.git", "", "srv/git/klyall", "kl-cli", ""},
+		{"/srv/git/kl-cli", "", "srv/git", "kl-cli", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			// When
+			u, err := ParseRemoteURL(tt.raw)
+
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, tt.host, u.Host)
+			assert.Equal(t, tt.owner, u.Owner)
+			assert.Equal(t, tt.name, u.Name)
+			assert.Equal(t, tt.browserURL, u.BrowserURL())
+		})
+	}
+}
+
+func TestParseRemoteURLErrors(t *testing.T) {
+	for _, raw := range []string{"", "https:///klyall/kl-cli", "git@github.com:"} {
+		t.Run(raw, func(t *testing.T) {
+			// When
+			_, err := ParseRemoteURL(raw)
+
+			// Then
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAge(t *testing.T) {
+	now := time.Date(2021, 11, 5, 10, 15, 0, 0, time.UTC)
+
+	assert.Equal(t, Age(now.Add(-30*time.Second), now), "just now")
+	assert.Equal(t, Age(now.Add(-time.Hour), now), "1 hour")
+	assert.Equal(t, Age(now.Add(-50*time.Hour), now), "2 days")
+	assert.Equal(t, Age(now.AddDate(0, 0, -400), now), "1 year")
+	assert.Equal(t, Age(time.Time{}, now), "")
+}
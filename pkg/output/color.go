@@ -0,0 +1,112 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gookit/color"
+	"golang.org/x/term"
+)
+
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+// themeColors are the palette entries that can be set in the theme section of
+// the config file.
+var themeColors = map[string]*color.Color{
+	"error":   &ErrorColor,
+	"debug":   &DebugColor,
+	"info":    &InfoColor,
+	"pass":    &PassColor,
+	"success": &SuccessColor,
+	"warn":    &WarnColor,
+}
+
+func ParseColorMode(mode string) (ColorMode, error) {
+	switch m := ColorMode(strings.ToLower(mode)); m {
+	case ColorAuto, ColorAlways, ColorNever:
+		return m, nil
+	case "":
+		return ColorAuto, nil
+	}
+
+	return "", fmt.Errorf("invalid color mode '%s', expected one of: auto, always, never", mode)
+}
+
+// SetColorMode turns colour on or off for all rendering. In auto mode colour is
+// only used when w is a terminal and the NO_COLOR environment variable is not
+// set; always and never override NO_COLOR.
+func SetColorMode(mode ColorMode, w io.Writer) {
+	switch mode {
+	case ColorAlways:
+		color.Enable = true
+		color.ForceColor()
+	case ColorNever:
+		color.Enable = false
+	default:
+		color.Enable = os.Getenv("NO_COLOR") == "" && IsTerminal(w)
+	}
+}
+
+// IsTerminal reports whether w writes to a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// SetTheme replaces palette entries with the named colours in theme, e.g.
+// {"warn": "magenta"}.
+func SetTheme(theme map[string]string) error {
+	for name, colorName := range theme {
+		entry, ok := themeColors[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("unknown theme color '%s', expected one of: %s", name, strings.Join(themeColorNames(), ", "))
+		}
+
+		c, err := parseColor(colorName)
+		if err != nil {
+			return err
+		}
+
+		*entry = c
+	}
+
+	return nil
+}
+
+func parseColor(name string) (color.Color, error) {
+	lower := strings.ToLower(name)
+
+	if lower == "gray" || lower == "grey" {
+		return color.FgGray, nil
+	}
+
+	if c, ok := color.FgColors[lower]; ok {
+		return c, nil
+	}
+
+	for n, c := range color.ExFgColors {
+		if strings.ToLower(n) == lower {
+			return c, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown color '%s'", name)
+}
+
+func themeColorNames() []string {
+	var names []string
+	for name := range themeColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
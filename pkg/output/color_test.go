@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	// Tests assert on the colour codes, which would otherwise depend on the terminal running them
+	SetColorMode(ColorAlways, nil)
+	os.Exit(m.Run())
+}
+
+func TestColorModeNever(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := SStdOut{
+		Out: &buf,
+	}
+
+	SetColorMode(ColorNever, &buf)
+	defer SetColorMode(ColorAlways, nil)
+
+	// When
+	testee.Error("Error message")
+
+	// Then
+	output := buf.String()
+
+	assert.Equal(t, output, "ERROR   Error message\n")
+}
+
+func TestColorModeAutoWhenNotTerminal(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := SStdOut{
+		Out: &buf,
+	}
+
+	SetColorMode(ColorAuto, &buf)
+	defer SetColorMode(ColorAlways, nil)
+
+	// When
+	testee.Success("Success message")
+
+	// Then
+	output := buf.String()
+
+	assert.Equal(t, output, "SUCCESS Success message\n")
+}
+
+func TestParseColorMode(t *testing.T) {
+	mode, err := ParseColorMode("Never")
+	assert.NoError(t, err)
+	assert.Equal(t, ColorNever, mode)
+
+	_, err = ParseColorMode("sometimes")
+	assert.Error(t, err)
+}
+
+func TestSetTheme(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := SStdOut{
+		Out: &buf,
+	}
+
+	original := WarnColor
+	defer func() { WarnColor = original }()
+
+	// When
+	err := SetTheme(map[string]string{"warn": "lightMagenta"})
+	testee.Warn("Warn message")
+
+	// Then
+	output := buf.String()
+
+	assert.NoError(t, err)
+	assert.Equal(t, output, "\x1b[95mWARN\x1b[0m    Warn message\n")
+}
+
+func TestSetThemeErrors(t *testing.T) {
+	assert.Error(t, SetTheme(map[string]string{"warning": "red"}))
+	assert.Error(t, SetTheme(map[string]string{"warn": "mauve"}))
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONOutputter writes all results and the summary as a single JSON document
+// once all results have been received. Log messages are written to a separate
+// writer so the document stays valid.
+type JSONOutputter struct {
+	SStdOut
+
+	out     io.Writer
+	results []Result
+	summary *Summary
+}
+
+type jsonDocument struct {
+	Results []Result     `json:"results"`
+	Summary *jsonSummary `json:"summary,omitempty"`
+}
+
+type jsonSummary struct {
+	Summary
+	Elapsed string `json:"elapsed"`
+}
+
+func NewJSONOutputter(out io.Writer, log io.Writer, level Level) *JSONOutputter {
+	return &JSONOutputter{
+		SStdOut: SStdOut{
+			Out:   log,
+			Level: level,
+		},
+		out:     out,
+		results: []Result{},
+	}
+}
+
+// Result is always kept, as quiet mode only applies to log messages.
+func (j *JSONOutputter) Result(result Result) {
+	j.results = append(j.results, result)
+}
+
+func (j *JSONOutputter) Summary(summary Summary) {
+	j.summary = &summary
+}
+
+func (j *JSONOutputter) Close() error {
+	document := jsonDocument{
+		Results: j.results,
+	}
+
+	if j.summary != nil {
+		document.Summary = &jsonSummary{
+			Summary: *j.summary,
+			Elapsed: j.summary.Elapsed.String(),
+		}
+	}
+
+	encoder := json.NewEncoder(j.out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(document)
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONOutputterWritesOneDocument(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee := NewJSONOutputter(&out, &log, DefaultLevel)
+
+	summary := Summary{Elapsed: 1500 * time.Millisecond}
+	summary.Count("Up to date")
+
+	// When
+	testee.Info("Fetching")
+	testee.Result(Result{Repository: "repo-a", Operation: "fetch", Outcome: OK, Message: "Up to date"})
+	testee.Summary(summary)
+	err := testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+
+	var document map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &document))
+
+	results := document["results"].([]interface{})
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].(map[string]interface{})["outcome"], "ok")
+	assert.Equal(t, document["summary"].(map[string]interface{})["elapsed"], "1.5s")
+	assert.Contains(t, log.String(), "Fetching")
+}
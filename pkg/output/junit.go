@@ -0,0 +1,298 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Conditions of a result that can be reported as failures by a JUnitOutputter.
+// Results that failed are always reported as failures.
+const (
+	FailOnError     = "error"
+	FailOnWarn      = "warn"
+	FailOnDirty     = "dirty"
+	FailOnUntracked = "untracked"
+	FailOnAhead     = "ahead"
+	FailOnBehind    = "behind"
+)
+
+var FailOnConditions = []string{FailOnError, FailOnWarn, FailOnDirty, FailOnUntracked, FailOnAhead, FailOnBehind}
+
+// failOnFields are the fields of the results each condition is read from.
+var failOnFields = map[string][]string{
+	FailOnDirty:     {"staged", "unstaged"},
+	FailOnUntracked: {"untracked"},
+	FailOnAhead:     {"ahead"},
+	FailOnBehind:    {"behind"},
+}
+
+// JUnitOutputter writes the results as a JUnit XML report, so the health of
+// the repositories can be shown by CI test dashboards. Each repository is a
+// test case, which fails when any of its results failed or matches one of the
+// FailOn conditions. Log messages and the summary are written to a separate
+// writer.
+type JUnitOutputter struct {
+	SStdOut
+	FailOn []string
+
+	out          io.Writer
+	repositories []string
+	results      map[string][]Result
+	summary      *Summary
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// ValidateFailOn checks the conditions are all FailOnConditions.
+func ValidateFailOn(failOn []string) error {
+	for _, condition := range failOn {
+		if !containsString(FailOnConditions, condition) {
+			return fmt.Errorf("unknown fail on condition '%s', expected one of: %s", condition, strings.Join(FailOnConditions, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ValidateFailOnFields checks the conditions are all FailOnConditions that can
+// be read from the fields the results have, so a condition is never ignored.
+func ValidateFailOnFields(failOn []string, fields []string) error {
+	if err := ValidateFailOn(failOn); err != nil {
+		return err
+	}
+
+	for _, condition := range failOn {
+		for _, field := range failOnFields[condition] {
+			if !containsString(fields, field) {
+				return fmt.Errorf("fail on condition '%s' cannot be used, as the results have no '%s' field", condition, field)
+			}
+		}
+	}
+
+	return nil
+}
+
+func NewJUnitOutputter(out io.Writer, log io.Writer, level Level, failOn []string) (*JUnitOutputter, error) {
+	if err := ValidateFailOn(failOn); err != nil {
+		return nil, err
+	}
+
+	return &JUnitOutputter{
+		SStdOut: SStdOut{
+			Out:   log,
+			Level: level,
+		},
+		FailOn:  failOn,
+		out:     out,
+		results: map[string][]Result{},
+	}, nil
+}
+
+// Result is always kept, as quiet mode only applies to log messages.
+func (j *JUnitOutputter) Result(result Result) {
+	if _, ok := j.results[result.Repository]; !ok {
+		j.repositories = append(j.repositories, result.Repository)
+	}
+
+	j.results[result.Repository] = append(j.results[result.Repository], result)
+}
+
+func (j *JUnitOutputter) Summary(summary Summary) {
+	j.summary = &summary
+}
+
+func (j *JUnitOutputter) Close() error {
+	suite := junitTestSuite{
+		Name: "kl",
+		Time: "0",
+	}
+
+	if j.summary != nil {
+		suite.Time = fmt.Sprintf("%.3f", j.summary.Elapsed.Seconds())
+	}
+
+	for _, repository := range j.repositories {
+		testCase := j.testCase(repository, j.results[repository])
+
+		if suite.Name == "kl" && testCase.ClassName != "" {
+			suite.Name = "kl " + testCase.ClassName
+		}
+
+		suite.Tests++
+
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+
+		if testCase.Skipped != nil {
+			suite.Skipped++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	document := junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(j.out, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(j.out)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(j.out, "\n"); err != nil {
+		return err
+	}
+
+	if j.summary != nil {
+		j.SStdOut.Summary(*j.summary)
+	}
+
+	return nil
+}
+
+// testCase combines the results of a repository. The repository is skipped
+// when all of its results were skipped.
+func (j *JUnitOutputter) testCase(repository string, results []Result) junitTestCase {
+	testCase := junitTestCase{
+		Name:      repository,
+		ClassName: results[0].Operation,
+		Time:      "0",
+	}
+
+	var failures, output []string
+	var failureType string
+	skipped := true
+
+	for _, result := range results {
+		output = append(output, resultLine(result))
+
+		if result.Outcome != Skipped {
+			skipped = false
+		}
+
+		if condition, message, failed := j.failed(result); failed {
+			failures = append(failures, message)
+
+			if failureType == "" {
+				failureType = condition
+			}
+		}
+	}
+
+	switch {
+	case len(failures) > 0:
+		testCase.Failure = &junitMessage{
+			Message: failures[0],
+			Type:    failureType,
+			Text:    strings.Join(failures, "\n"),
+		}
+	case skipped:
+		testCase.Skipped = &junitMessage{
+			Message: results[0].Message,
+		}
+	}
+
+	testCase.SystemOut = strings.Join(output, "\n")
+
+	return testCase
+}
+
+// failed returns the first condition the result matches, if any, with a
+// message describing the failure.
+func (j *JUnitOutputter) failed(result Result) (string, string, bool) {
+	if result.Outcome == Failure {
+		return FailOnError, result.Message, true
+	}
+
+	for _, condition := range j.FailOn {
+		switch {
+		case condition == FailOnWarn && result.Outcome == Warning:
+			return condition, result.Message, true
+		case condition == FailOnDirty && (intField(result, "staged") > 0 || intField(result, "unstaged") > 0):
+			return condition, fmt.Sprintf("Uncommitted changes, staged: %d, unstaged: %d",
+				intField(result, "staged"), intField(result, "unstaged")), true
+		case condition == FailOnUntracked && intField(result, "untracked") > 0:
+			return condition, fmt.Sprintf("Untracked files: %d", intField(result, "untracked")), true
+		case condition == FailOnAhead && intField(result, "ahead") > 0:
+			return condition, fmt.Sprintf("Commits ahead: %d", intField(result, "ahead")), true
+		case condition == FailOnBehind && intField(result, "behind") > 0:
+			return condition, fmt.Sprintf("Commits behind: %d", intField(result, "behind")), true
+		}
+	}
+
+	return "", "", false
+}
+
+func resultLine(result Result) string {
+	line := fmt.Sprintf("%s: %s", result.Outcome.Label(), result.Message)
+
+	for _, f := range result.Fields {
+		if f.Value != "" {
+			line += fmt.Sprintf(", %s: %s", f.Name, f.Value)
+		}
+	}
+
+	return line
+}
+
+func intField(result Result, name string) int {
+	value, _ := result.Field(name)
+	i, _ := strconv.Atoi(value)
+	return i
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func junitTestResults() []Result {
+	return []Result{
+		{Repository: "repo-a", Operation: "status", Outcome: OK, Message: "Up to date",
+			Fields: []Field{{Name: "behind", Value: "2"}}},
+		{Repository: "repo-b", Operation: "status", Outcome: Failure, Message: "Unable to read git repository: exit status 128"},
+		{Repository: "plain", Operation: "status", Outcome: Skipped, Message: "Not versioned"},
+	}
+}
+
+func TestJUnitOutputterReportsFailedResults(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee, err := NewJUnitOutputter(&out, &log, DefaultLevel, nil)
+	assert.Nil(t, err)
+
+	// When
+	for _, result := range junitTestResults() {
+		testee.Result(result)
+	}
+	err = testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `<testsuite name="kl status" tests="3" failures="1" skipped="1" time="0">`)
+	assert.Contains(t, out.String(), `<failure message="Unable to read git repository: exit status 128" type="error">`)
+	assert.Contains(t, out.String(), `<skipped message="Not versioned"></skipped>`)
+}
+
+func TestJUnitOutputterFailsOnPolicy(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee, err := NewJUnitOutputter(&out, &log, DefaultLevel, []string{FailOnBehind})
+	assert.Nil(t, err)
+
+	// When
+	for _, result := range junitTestResults() {
+		testee.Result(result)
+	}
+	err = testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `failures="2"`)
+	assert.Contains(t, out.String(), `<failure message="Commits behind: 2" type="behind">`)
+}
+
+func TestJUnitOutputterRejectsUnknownCondition(t *testing.T) {
+	// When
+	_, err := NewJUnitOutputter(&bytes.Buffer{}, &bytes.Buffer{}, DefaultLevel, []string{"dirt"})
+
+	// Then
+	assert.NotNil(t, err)
+}
+
+func TestValidateFailOn(t *testing.T) {
+	assert.Nil(t, ValidateFailOn([]string{FailOnDirty, FailOnBehind}))
+	assert.EqualError(t, ValidateFailOn([]string{"dirt"}),
+		"unknown fail on condition 'dirt', expected one of: error, warn, dirty, untracked, ahead, behind")
+}
+
+func TestValidateFailOnFields(t *testing.T) {
+	fields := []string{"branch", "ahead", "behind", "staged", "unstaged", "untracked"}
+
+	assert.Nil(t, ValidateFailOnFields([]string{FailOnDirty, FailOnUntracked, FailOnAhead, FailOnBehind}, fields))
+	assert.Nil(t, ValidateFailOnFields([]string{FailOnError, FailOnWarn}, nil))
+	assert.EqualError(t, ValidateFailOnFields([]string{FailOnDirty}, []string{"branch"}),
+		"fail on condition 'dirty' cannot be used, as the results have no 'staged' field")
+	assert.EqualError(t, ValidateFailOnFields([]string{FailOnBehind}, nil),
+		"fail on condition 'behind' cannot be used, as the results have no 'behind' field")
+	assert.EqualError(t, ValidateFailOnFields([]string{"dirt"}, fields),
+		"unknown fail on condition 'dirt', expected one of: error, warn, dirty, untracked, ahead, behind")
+}
+
+func TestNewRejectsFailOnWithoutFields(t *testing.T) {
+	// When
+	_, err := New(JUnitFormat, &bytes.Buffer{}, &bytes.Buffer{}, Options{FailOn: []string{FailOnDirty}})
+
+	// Then
+	assert.EqualError(t, err, "fail on condition 'dirty' cannot be used, as the results have no 'staged' field")
+}
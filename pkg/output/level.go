@@ -0,0 +1,15 @@
+package output
+
+// Level of detail written by an Outputter. The zero value is the default
+// level, and each -v flag raises it by one.
+type Level int
+
+const (
+	// QuietLevel only outputs problems: warnings and errors
+	QuietLevel Level = iota - 1
+	DefaultLevel
+	// VerboseLevel adds the git commands run and their raw output
+	VerboseLevel
+	// TraceLevel adds the duration and exit code of every git command
+	TraceLevel
+)
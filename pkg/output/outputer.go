@@ -1,17 +1,26 @@
 package output
 
-import "github.com/gookit/color"
+import (
+	"fmt"
+	"io"
+	"strings"
 
+	"github.com/gookit/color"
+)
+
+// Outputter presents the results of a command, along with log messages about
+// how it is progressing. Close must be called once all results are reported,
+// as some implementations only write their results then.
 type Outputter interface {
+	Close() error
 	Debug(message interface{})
 	DebugBytes(message []byte)
+	Enabled(level Level) bool
 	Error(message interface{})
 	Info(message string)
-	RenderError(a ...interface{}) string
-	RenderInfo(a ...interface{}) string
-	RenderSuccess(a ...interface{}) string
-	RenderWarn(a ...interface{}) string
-	Success(message string)
+	Result(result Result)
+	Summary(summary Summary)
+	Trace(message interface{})
 	Warn(message string)
 }
 
@@ -21,3 +30,65 @@ var InfoColor = color.FgCyan
 var PassColor = color.FgCyan
 var SuccessColor = color.FgCyan
 var WarnColor = color.FgYellow
+
+const (
+	TableFormat    = "table"
+	TextFormat     = "text"
+	JSONFormat     = "json"
+	CSVFormat      = "csv"
+	MarkdownFormat = "markdown"
+	HTMLFormat     = "html"
+	JUnitFormat    = "junit"
+)
+
+var Formats = []string{TableFormat, TextFormat, JSONFormat, CSVFormat, MarkdownFormat, HTMLFormat, JUnitFormat}
+
+// Options of the Outputter created by New.
+type Options struct {
+	Level Level
+	// Columns of the table and report formats to show, in order
+	Columns []string
+	// Fields are the names of every field the results of the command can have,
+	// which the columns are checked against
+	Fields []string
+	// Template is used instead of the format when given
+	Template string
+	// FailOn are the conditions, other than errors, that are failures in the JUnit format
+	FailOn []string
+}
+
+// New creates the Outputter for format, writing results to out and, for
+// structured formats, log messages to log.
+func New(format string, out io.Writer, log io.Writer, options Options) (Outputter, error) {
+	level, columns := options.Level, options.Columns
+
+	if err := ValidateColumns(columns, options.Fields); err != nil {
+		return nil, err
+	}
+
+	if options.Template != "" {
+		return NewTemplateOutputter(out, log, level, options.Template)
+	}
+
+	switch strings.ToLower(format) {
+	case TableFormat, "":
+		return NewTableOutputter(out, level, columns), nil
+	case TextFormat:
+		return NewTextOutputter(out, level), nil
+	case JSONFormat:
+		return NewJSONOutputter(out, log, level), nil
+	case CSVFormat:
+		return NewCSVOutputter(out, log, level, columns), nil
+	case MarkdownFormat, "md":
+		return NewMarkdownOutputter(out, log, level, columns), nil
+	case HTMLFormat:
+		return NewHTMLOutputter(out, log, level, columns), nil
+	case JUnitFormat:
+		if err := ValidateFailOnFields(options.FailOn, options.Fields); err != nil {
+			return nil, err
+		}
+		return NewJUnitOutputter(out, log, level, options.FailOn)
+	}
+
+	return nil, fmt.Errorf("unknown output format '%s', expected one of: %s", format, strings.Join(Formats, ", "))
+}
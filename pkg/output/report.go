@@ -0,0 +1,242 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// reportOutputter holds the results and summary of a command to write as a
+// report once all of them have been received. Log messages are written to a
+// separate writer, so a report can be redirected to a file.
+type reportOutputter struct {
+	SStdOut
+	// Columns to show in order. Defaults to the status, name, fields and message.
+	Columns []string
+
+	out     io.Writer
+	results []Result
+	summary *Summary
+}
+
+func newReportOutputter(out io.Writer, log io.Writer, level Level, columns []string) reportOutputter {
+	return reportOutputter{
+		SStdOut: SStdOut{
+			Out:   log,
+			Level: level,
+		},
+		Columns: columns,
+		out:     out,
+	}
+}
+
+func (r *reportOutputter) Result(result Result) {
+	if result.Problem() || r.Enabled(DefaultLevel) {
+		r.results = append(r.results, result)
+	}
+}
+
+func (r *reportOutputter) Summary(summary Summary) {
+	r.summary = &summary
+}
+
+// CSVOutputter writes the selected columns of the results as CSV, with the
+// column names as the header row. The summary is written to the log.
+type CSVOutputter struct {
+	reportOutputter
+}
+
+func NewCSVOutputter(out io.Writer, log io.Writer, level Level, columns []string) *CSVOutputter {
+	return &CSVOutputter{newReportOutputter(out, log, level, columns)}
+}
+
+func (c *CSVOutputter) Close() error {
+	columns := selectColumns(c.results, c.Columns)
+
+	w := csv.NewWriter(c.out)
+
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+
+	if err := w.WriteAll(rows(c.results, columns)); err != nil {
+		return err
+	}
+
+	if c.summary != nil {
+		c.SStdOut.Summary(*c.summary)
+	}
+
+	return nil
+}
+
+// MarkdownOutputter writes the selected columns of the results as a GitHub
+// flavoured Markdown table followed by the summary.
+type MarkdownOutputter struct {
+	reportOutputter
+}
+
+func NewMarkdownOutputter(out io.Writer, log io.Writer, level Level, columns []string) *MarkdownOutputter {
+	return &MarkdownOutputter{newReportOutputter(out, log, level, columns)}
+}
+
+func (m *MarkdownOutputter) Close() error {
+	columns := selectColumns(m.results, m.Columns)
+
+	var b strings.Builder
+
+	var headers, separators []string
+	for _, name := range columns {
+		headers = append(headers, markdownCell(columnFor(name).Header))
+		separators = append(separators, "---")
+	}
+
+	b.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	b.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+
+	for _, row := range rows(m.results, columns) {
+		for i := range row {
+			row[i] = markdownCell(row[i])
+		}
+
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+
+	if m.summary != nil {
+		b.WriteString("\n")
+
+		for _, line := range strings.Split(m.summary.String(), "\n") {
+			b.WriteString(line + "  \n")
+		}
+	}
+
+	_, err := io.WriteString(m.out, b.String())
+	return err
+}
+
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// Files is implemented by result data that lists the files of a repository,
+// e.g. those with uncommitted changes, which are shown by the HTML report.
+type Files interface {
+	Files() []string
+}
+
+// HTMLOutputter writes a self-contained HTML page with a table of the selected
+// columns of the results, coloured by outcome, and the files of each
+// repository along with the summary.
+type HTMLOutputter struct {
+	reportOutputter
+}
+
+func NewHTMLOutputter(out io.Writer, log io.Writer, level Level, columns []string) *HTMLOutputter {
+	return &HTMLOutputter{newReportOutputter(out, log, level, columns)}
+}
+
+type htmlReport struct {
+	Title     string
+	Generated string
+	Headers   []string
+	// StatusCell is the index of the status column, which is coloured by
+	// outcome, or -1 when it is not selected
+	StatusCell int
+	Rows       []htmlRow
+	HasFiles   bool
+	Summary    []string
+}
+
+type htmlRow struct {
+	Outcome Outcome
+	Cells   []string
+	Files   []string
+}
+
+func (h *HTMLOutputter) Close() error {
+	columns := selectColumns(h.results, h.Columns)
+
+	report := htmlReport{
+		Title:      "kl report",
+		Generated:  time.Now().Format("2006-01-02 15:04:05"),
+		StatusCell: -1,
+	}
+
+	if len(h.results) > 0 && h.results[0].Operation != "" {
+		report.Title = fmt.Sprintf("kl %s report", h.results[0].Operation)
+	}
+
+	for i, name := range columns {
+		report.Headers = append(report.Headers, columnFor(name).Header)
+
+		if name == StatusColumn {
+			report.StatusCell = i
+		}
+	}
+
+	for i, cells := range rows(h.results, columns) {
+		row := htmlRow{
+			Outcome: h.results[i].Outcome,
+			Cells:   cells,
+		}
+
+		if files, ok := h.results[i].Data.(Files); ok {
+			row.Files = files.Files()
+			report.HasFiles = report.HasFiles || len(row.Files) > 0
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	if h.summary != nil {
+		report.Summary = strings.Split(h.summary.String(), "\n")
+	}
+
+	return htmlTemplate.Execute(h.out, report)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+tr.ok td.status { color: #1a7f37; }
+tr.warn td.status { color: #9a6700; }
+tr.error td.status { color: #cf222e; }
+tr.skipped { color: #6e7781; }
+details ul { margin: 4px 0; padding-left: 1.2em; font-family: monospace; }
+footer { margin-top: 1em; color: #6e7781; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}{{if .HasFiles}}<th>FILES</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- $hasFiles := .HasFiles}}
+{{- range .Rows}}
+<tr class="{{.Outcome}}">{{range $i, $cell := .Cells}}<td{{if eq $i $.StatusCell}} class="status"{{end}}>{{$cell}}</td>{{end}}
+{{- if $hasFiles}}<td>{{if .Files}}<details><summary>{{len .Files}} changed</summary><ul>{{range .Files}}<li>{{.}}</li>{{end}}</ul></details>{{end}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+<footer>
+{{- range .Summary}}
+<p>{{.}}</p>
+{{- end}}
+<p>Generated {{.Generated}}</p>
+</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reportTestData struct{}
+
+func (reportTestData) Files() []string {
+	return []string{"?? new.txt"}
+}
+
+func reportTestResults() []Result {
+	return []Result{
+		{Repository: "repo-a", Outcome: OK, Message: "Up to date", Fields: []Field{{Name: "branch", Value: "main"}}},
+		{Repository: "repo-b", Outcome: Warning, Message: "Changes to commit | push", Fields: []Field{{Name: "branch", Value: "dev"}},
+			Data: reportTestData{}},
+	}
+}
+
+func TestCSVOutputter(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee := NewCSVOutputter(&out, &log, DefaultLevel, []string{"name", "branch"})
+
+	// When
+	for _, result := range reportTestResults() {
+		testee.Result(result)
+	}
+	err := testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), "name,branch\nrepo-a,main\nrepo-b,dev\n")
+}
+
+func TestMarkdownOutputterEscapesCells(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee := NewMarkdownOutputter(&out, &log, DefaultLevel, []string{"name", "message"})
+
+	// When
+	for _, result := range reportTestResults() {
+		testee.Result(result)
+	}
+	err := testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), "| REPOSITORY NAME | MESSAGE |\n"+
+		"| --- | --- |\n"+
+		"| repo-a | Up to date |\n"+
+		"| repo-b | Changes to commit \\| push |\n")
+}
+
+func TestHTMLOutputterListsFiles(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee := NewHTMLOutputter(&out, &log, DefaultLevel, nil)
+
+	// When
+	for _, result := range reportTestResults() {
+		testee.Result(result)
+	}
+	err := testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `<tr class="warn"><td class="status">WARN</td><td>repo-b</td>`)
+	assert.Contains(t, out.String(), "<li>?? new.txt</li>")
+}
+
+func TestHTMLOutputterColoursStatusColumn(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee := NewHTMLOutputter(&out, &log, DefaultLevel, []string{"name", "status"})
+
+	// When
+	for _, result := range reportTestResults() {
+		testee.Result(result)
+	}
+	err := testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `<tr class="warn"><td>repo-b</td><td class="status">WARN</td>`)
+}
+
+func TestHTMLOutputterWithoutStatusColumn(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee := NewHTMLOutputter(&out, &log, DefaultLevel, []string{"name", "message"})
+
+	// When
+	for _, result := range reportTestResults() {
+		testee.Result(result)
+	}
+	err := testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.NotContains(t, out.String(), `<td class="status">`)
+}
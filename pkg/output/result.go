@@ -0,0 +1,92 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// Outcome classifies a result so every Outputter can present it consistently.
+type Outcome string
+
+const (
+	OK      Outcome = "ok"
+	Warning Outcome = "warn"
+	Failure Outcome = "error"
+	Skipped Outcome = "skipped"
+)
+
+// Result of an operation on a single repository.
+type Result struct {
+	Repository string      `json:"repository"`
+	Operation  string      `json:"operation"`
+	Outcome    Outcome     `json:"outcome"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	// Group is shown as a heading above the results of the group by text based
+	// outputs, so the results of a group must be reported together
+	Group string `json:"group,omitempty"`
+	// Fields are shown as extra columns by text based outputs
+	Fields []Field `json:"-"`
+	// Details are lines shown below the result by text based outputs, e.g. the
+	// changed files of a repository
+	Details []string `json:"-"`
+}
+
+// Field is a named value of a result, e.g. the branch of a repository. The name
+// is used to select the field with --columns.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Problem reports whether the result should still be shown in quiet mode.
+func (r Result) Problem() bool {
+	return r.Outcome == Warning || r.Outcome == Failure
+}
+
+// Field returns the value of the named field, or the repository, outcome or
+// message for the built in name, status and message columns.
+func (r Result) Field(name string) (string, bool) {
+	switch name {
+	case NameColumn:
+		return r.Repository, true
+	case StatusColumn:
+		return r.Outcome.Label(), true
+	case MessageColumn:
+		return r.Message, true
+	}
+
+	for _, f := range r.Fields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func (o Outcome) Color() color.Color {
+	switch o {
+	case Warning:
+		return WarnColor
+	case Failure:
+		return ErrorColor
+	case Skipped:
+		return DebugColor
+	}
+
+	return SuccessColor
+}
+
+// Label is the upper case name of the outcome shown in text outputs.
+func (o Outcome) Label() string {
+	switch o {
+	case OK:
+		return "SUCCESS"
+	case "":
+		return ""
+	}
+
+	return strings.ToUpper(string(o))
+}
@@ -5,17 +5,37 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 )
 
+// SStdOut writes messages at or below Level, with warnings and errors always
+// written. Results are written as lines of text as they are received.
 type SStdOut struct {
-	Out io.Writer
+	Out   io.Writer
+	Level Level
+}
+
+func (s SStdOut) Enabled(level Level) bool {
+	return level <= s.Level
 }
 
 func (s SStdOut) Debug(message interface{}) {
-	fmt.Fprintf(s.Out, "%s\n", DebugColor.Render(message))
+	if s.Enabled(VerboseLevel) {
+		fmt.Fprintf(s.Out, "%s\n", DebugColor.Render(message))
+	}
+}
+
+func (s SStdOut) Trace(message interface{}) {
+	if s.Enabled(TraceLevel) {
+		fmt.Fprintf(s.Out, "%s\n", DebugColor.Render(message))
+	}
 }
 
 func (s SStdOut) DebugBytes(content []byte) {
+	if !s.Enabled(VerboseLevel) {
+		return
+	}
+
 	r := bytes.NewReader(content)
 	scanner := bufio.NewScanner(r)
 
@@ -33,33 +53,93 @@ func (s SStdOut) Error(message interface{}) {
 }
 
 func (s SStdOut) Info(message string) {
-	s.printMessage(InfoColor.Render("INFO"), message)
+	if s.Enabled(DefaultLevel) {
+		s.printMessage(InfoColor.Render("INFO"), message)
+	}
 }
 
 func (s SStdOut) Success(message string) {
-	s.printMessage(SuccessColor.Render("SUCCESS"), message)
+	if s.Enabled(DefaultLevel) {
+		s.printMessage(SuccessColor.Render("SUCCESS"), message)
+	}
+}
+
+func (s SStdOut) Summary(summary Summary) {
+	if !s.Enabled(DefaultLevel) {
+		return
+	}
+
+	fmt.Fprintln(s.Out)
+
+	for _, line := range strings.Split(summary.String(), "\n") {
+		s.printMessage(InfoColor.Render("SUMMARY"), line)
+	}
 }
 
 func (s SStdOut) Warn(message string) {
 	s.printMessage(WarnColor.Render("WARN"), message)
 }
 
-func (s SStdOut) RenderError(a ...interface{}) string {
-	return ErrorColor.Render(a)
+// Result writes the result as soon as it is received, with any fields after
+// the message.
+func (s SStdOut) Result(result Result) {
+	if !result.Problem() && !s.Enabled(DefaultLevel) {
+		return
+	}
+
+	message := result.Outcome.Color().Render(result.Message)
+
+	var fields []string
+	for _, f := range result.Fields {
+		if f.Value != "" {
+			fields = append(fields, fmt.Sprintf("%s: %s", f.Name, f.Value))
+		}
+	}
+
+	if len(fields) > 0 {
+		message += DebugColor.Render(" (" + strings.Join(fields, ", ") + ")")
+	}
+
+	s.printMessage(result.Outcome.Color().Render(result.Outcome.Label()), fmt.Sprintf("%-50s %s", result.Repository, message))
+
+	for _, line := range result.Details {
+		fmt.Fprintf(s.Out, "%s%s\n", detailIndent, line)
+	}
+}
+
+func (s SStdOut) Close() error {
+	return nil
 }
 
-func (s SStdOut) RenderInfo(a ...interface{}) string {
-	return InfoColor.Render(a)
+// TextOutputter writes results as lines of text as they are received, with a
+// heading whenever the group of the results changes.
+type TextOutputter struct {
+	SStdOut
+	group string
 }
 
-func (s SStdOut) RenderSuccess(a ...interface{}) string {
-	return SuccessColor.Render(a)
+func NewTextOutputter(w io.Writer, level Level) *TextOutputter {
+	return &TextOutputter{
+		SStdOut: SStdOut{
+			Out:   w,
+			Level: level,
+		},
+	}
 }
 
-func (s SStdOut) RenderWarn(a ...interface{}) string {
-	return WarnColor.Render(a)
+func (t *TextOutputter) Result(result Result) {
+	if !result.Problem() && !t.Enabled(DefaultLevel) {
+		return
+	}
+
+	if result.Group != t.group {
+		t.group = result.Group
+		fmt.Fprintln(t.Out, InfoColor.Render(t.group))
+	}
+
+	t.SStdOut.Result(result)
 }
 
-func (s SStdOut) printMessage(status, message interface{}) {
-	fmt.Fprintf(s.Out, "%-16s %s\n", status, message)
+func (s SStdOut) printMessage(status string, message interface{}) {
+	fmt.Fprintf(s.Out, "%s %s\n", Pad(status, len("SUCCESS")), message)
 }
@@ -2,7 +2,9 @@ package output
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -54,3 +56,84 @@ func TestSuccessMessage(t *testing.T) {
 
 	assert.Equal(t, output, "\x1b[36mSUCCESS\x1b[0m Success message\n")
 }
+
+func TestSummaryMessage(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := SStdOut{
+		Out: &buf,
+	}
+
+	summary := NewSummary()
+	summary.Count("Up to date")
+	summary.Count("Changes to pull")
+	summary.Count("Up to date")
+	summary.CountError()
+	summary.CountNotVersioned()
+	summary.Elapsed = 1500 * time.Millisecond
+
+	// When
+	testee.Summary(*summary)
+
+	// Then
+	output := buf.String()
+
+	assert.Equal(t, output, "\n"+
+		"\x1b[36mSUMMARY\x1b[0m Up to date: 2, Changes to pull: 1\n"+
+		"\x1b[36mSUMMARY\x1b[0m Errors: 1, Not versioned: 1, Elapsed: 1.5s\n")
+}
+
+func TestQuietLevel(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := SStdOut{
+		Out:   &buf,
+		Level: QuietLevel,
+	}
+
+	// When
+	testee.Success("Success message")
+	testee.Info("Info message")
+	testee.Warn("Warn message")
+
+	// Then
+	output := buf.String()
+
+	assert.Equal(t, output, "\x1b[33mWARN\x1b[0m    Warn message\n")
+}
+
+func TestVerboseLevel(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := SStdOut{
+		Out:   &buf,
+		Level: VerboseLevel,
+	}
+
+	// When
+	testee.Debug("Debug message")
+	testee.Trace("Trace message")
+
+	// Then
+	output := buf.String()
+
+	assert.Equal(t, output, "\x1b[90mDebug message\x1b[0m\n")
+}
+
+func TestTextOutputterGroupHeadings(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := NewTextOutputter(&buf, DefaultLevel)
+
+	// When
+	testee.Result(Result{Repository: "repo-a", Outcome: OK, Message: "a", Group: "github.com/klyall"})
+	testee.Result(Result{Repository: "repo-b", Outcome: OK, Message: "b", Group: "github.com/klyall"})
+	testee.Result(Result{Repository: "plain", Outcome: Skipped, Message: "Not versioned", Group: "Other"})
+
+	// Then
+	output := buf.String()
+
+	assert.Equal(t, strings.Count(output, "github.com/klyall"), 1)
+	assert.Less(t, strings.Index(output, "github.com/klyall"), strings.Index(output, "repo-a"))
+	assert.Less(t, strings.Index(output, "repo-b"), strings.Index(output, "Other"))
+}
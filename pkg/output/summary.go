@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary is the aggregate of the results of a command across all directories.
+type Summary struct {
+	Totals       []Total       `json:"totals"`
+	Errors       int           `json:"errors"`
+	NotVersioned int           `json:"notVersioned"`
+	Elapsed      time.Duration `json:"elapsed"`
+
+	start time.Time
+}
+
+// Total is the number of directories with the same result message.
+type Total struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+func NewSummary() *Summary {
+	return &Summary{
+		start: time.Now(),
+	}
+}
+
+// Count adds one to the total for message, keeping totals in the order first seen.
+func (s *Summary) Count(message string) {
+	for i := range s.Totals {
+		if s.Totals[i].Message == message {
+			s.Totals[i].Count++
+			return
+		}
+	}
+
+	s.Totals = append(s.Totals, Total{Message: message, Count: 1})
+}
+
+// Add counts a failed result as an error and any other result by its message.
+func (s *Summary) Add(result Result) {
+	if result.Outcome == Failure {
+		s.CountError()
+		return
+	}
+
+	s.Count(result.Message)
+}
+
+func (s *Summary) CountError() {
+	s.Errors++
+}
+
+func (s *Summary) CountNotVersioned() {
+	s.NotVersioned++
+}
+
+// Finish records the time taken since the summary was created.
+func (s *Summary) Finish() Summary {
+	s.Elapsed = time.Since(s.start).Round(time.Millisecond)
+	return *s
+}
+
+func (s Summary) String() string {
+	var totals []string
+	for _, t := range s.Totals {
+		totals = append(totals, fmt.Sprintf("%s: %d", t.Message, t.Count))
+	}
+
+	counts := []string{
+		fmt.Sprintf("Errors: %d", s.Errors),
+		fmt.Sprintf("Not versioned: %d", s.NotVersioned),
+	}
+
+	if s.Elapsed > 0 {
+		counts = append(counts, fmt.Sprintf("Elapsed: %s", s.Elapsed))
+	}
+
+	if len(totals) == 0 {
+		return strings.Join(counts, ", ")
+	}
+
+	return strings.Join(totals, ", ") + "\n" + strings.Join(counts, ", ")
+}
@@ -0,0 +1,264 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+const ellipsis = "…"
+const resetCode = "\x1b[0m"
+const columnGap = 1
+const defaultMinWidth = 8
+const detailIndent = "    "
+
+var escapeCode = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+var leadingEscapeCode = regexp.MustCompile("^" + escapeCode.String())
+
+// Column of a table. Columns are shrunk no smaller than MinWidth, or the width
+// of their header, when the table is wider than the terminal.
+type Column struct {
+	Header   string
+	MinWidth int
+}
+
+// Table lays out rows of cells in aligned columns, measuring the width of each
+// cell as displayed so colour codes and wide characters do not misalign it.
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	// Details are indented lines written below a row, by row index, which do
+	// not affect the width of the columns
+	Details map[int][]string
+	// Headings are lines written above a row, by row index, which do not
+	// affect the width of the columns
+	Headings map[int]string
+	// Width the table must fit in, or zero for no limit
+	Width int
+}
+
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// AddHeading adds a line to be written above the next row.
+func (t *Table) AddHeading(heading string) {
+	if t.Headings == nil {
+		t.Headings = map[int]string{}
+	}
+
+	t.Headings[len(t.Rows)] = heading
+}
+
+// AddDetails adds lines to be written below the last row.
+func (t *Table) AddDetails(lines ...string) {
+	if len(t.Rows) == 0 || len(lines) == 0 {
+		return
+	}
+
+	if t.Details == nil {
+		t.Details = map[int][]string{}
+	}
+
+	row := len(t.Rows) - 1
+	t.Details[row] = append(t.Details[row], lines...)
+}
+
+func (t Table) Render(w io.Writer) error {
+	widths := t.columnWidths()
+
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Header
+	}
+
+	if err := t.renderRow(w, widths, header); err != nil {
+		return err
+	}
+
+	for i, row := range t.Rows {
+		if heading, ok := t.Headings[i]; ok {
+			if t.Width > 0 {
+				heading = Truncate(heading, t.Width)
+			}
+
+			if _, err := fmt.Fprintln(w, heading); err != nil {
+				return err
+			}
+		}
+
+		if err := t.renderRow(w, widths, row); err != nil {
+			return err
+		}
+
+		for _, line := range t.Details[i] {
+			if t.Width > 0 {
+				line = Truncate(line, t.Width-len(detailIndent))
+			}
+
+			if _, err := fmt.Fprintln(w, detailIndent+line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t Table) renderRow(w io.Writer, widths []int, cells []string) error {
+	var line strings.Builder
+
+	for i, width := range widths {
+		var cell string
+		if i < len(cells) {
+			cell = Truncate(cells[i], width)
+		}
+
+		if i == len(widths)-1 {
+			line.WriteString(cell)
+		} else {
+			line.WriteString(Pad(cell, width+columnGap))
+		}
+	}
+
+	_, err := fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	return err
+}
+
+// columnWidths sizes each column to its widest cell, then shrinks the widest
+// columns a character at a time until the table fits.
+func (t Table) columnWidths() []int {
+	widths := make([]int, len(t.Columns))
+	minWidths := make([]int, len(t.Columns))
+
+	for i, c := range t.Columns {
+		widths[i] = DisplayWidth(c.Header)
+
+		minWidths[i] = c.MinWidth
+		if minWidths[i] == 0 {
+			minWidths[i] = defaultMinWidth
+		}
+		if minWidths[i] < widths[i] {
+			minWidths[i] = widths[i]
+		}
+	}
+
+	for _, row := range t.Rows {
+		for i := range widths {
+			if i < len(row) {
+				if w := DisplayWidth(row[i]); w > widths[i] {
+					widths[i] = w
+				}
+			}
+		}
+	}
+
+	if t.Width <= 0 {
+		return widths
+	}
+
+	for total(widths) > t.Width {
+		widest := -1
+		for i := range widths {
+			if widths[i] > minWidths[i] && (widest == -1 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+
+		if widest == -1 {
+			break
+		}
+
+		widths[widest]--
+	}
+
+	return widths
+}
+
+func total(widths []int) int {
+	sum := (len(widths) - 1) * columnGap
+	for _, w := range widths {
+		sum += w
+	}
+	return sum
+}
+
+// DisplayWidth is the number of terminal cells s occupies, ignoring colour codes.
+func DisplayWidth(s string) int {
+	return runewidth.StringWidth(escapeCode.ReplaceAllString(s, ""))
+}
+
+// Pad appends spaces to s until it is width cells wide.
+func Pad(s string, width int) string {
+	if padding := width - DisplayWidth(s); padding > 0 {
+		return s + strings.Repeat(" ", padding)
+	}
+
+	return s
+}
+
+// Truncate shortens s to at most width cells, ending it with an ellipsis.
+// Colour codes are kept and reset after the ellipsis.
+func Truncate(s string, width int) string {
+	if DisplayWidth(s) <= width {
+		return s
+	}
+
+	limit := width - runewidth.StringWidth(ellipsis)
+
+	var b strings.Builder
+	var used int
+	var coloured bool
+
+	for len(s) > 0 {
+		if code := leadingEscapeCode.FindString(s); code != "" {
+			b.WriteString(code)
+			s = s[len(code):]
+			coloured = true
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s)
+		w := runewidth.RuneWidth(r)
+		if used+w > limit {
+			break
+		}
+
+		b.WriteRune(r)
+		used += w
+		s = s[size:]
+	}
+
+	if limit >= 0 {
+		b.WriteString(ellipsis)
+	}
+
+	if coloured {
+		b.WriteString(resetCode)
+	}
+
+	return b.String()
+}
+
+// TerminalWidth is the width of the terminal w writes to, or of the COLUMNS
+// environment variable when it is not a terminal. Zero means unknown.
+func TerminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil {
+			return width
+		}
+	}
+
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+		return width
+	}
+
+	return 0
+}
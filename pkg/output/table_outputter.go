@@ -0,0 +1,175 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Built in columns of a TableOutputter, which can be selected along with the
+// fields of the results.
+const (
+	StatusColumn  = "status"
+	NameColumn    = "name"
+	MessageColumn = "message"
+)
+
+// TableOutputter writes results as a table, fitted to the width of the
+// terminal, once all of them have been received.
+type TableOutputter struct {
+	SStdOut
+	// Columns to show in order. Defaults to the status, name, fields and message.
+	Columns []string
+
+	results []Result
+	summary *Summary
+}
+
+func NewTableOutputter(w io.Writer, level Level, columns []string) *TableOutputter {
+	return &TableOutputter{
+		SStdOut: SStdOut{
+			Out:   w,
+			Level: level,
+		},
+		Columns: columns,
+	}
+}
+
+func (t *TableOutputter) Result(result Result) {
+	if result.Problem() || t.Enabled(DefaultLevel) {
+		t.results = append(t.results, result)
+	}
+}
+
+// Summary is held back until the table has been written.
+func (t *TableOutputter) Summary(summary Summary) {
+	t.summary = &summary
+}
+
+func (t *TableOutputter) Close() error {
+	if len(t.results) > 0 {
+		if err := t.table().Render(t.Out); err != nil {
+			return err
+		}
+	}
+
+	if t.summary != nil {
+		t.SStdOut.Summary(*t.summary)
+	}
+
+	return nil
+}
+
+func (t *TableOutputter) table() Table {
+	columns := selectColumns(t.results, t.Columns)
+
+	table := Table{
+		Width: TerminalWidth(t.Out),
+	}
+
+	for _, name := range columns {
+		table.Columns = append(table.Columns, columnFor(name))
+	}
+
+	var group string
+
+	for _, result := range t.results {
+		if result.Group != group {
+			group = result.Group
+			table.AddHeading(InfoColor.Render(group))
+		}
+
+		var cells []string
+
+		for _, name := range columns {
+			value, _ := result.Field(name)
+
+			if name == StatusColumn || name == MessageColumn {
+				value = result.Outcome.Color().Render(value)
+			}
+
+			cells = append(cells, value)
+		}
+
+		table.AddRow(cells...)
+		table.AddDetails(result.Details...)
+	}
+
+	return table
+}
+
+// selectColumns returns the selected columns, which are checked by
+// ValidateColumns when the Outputter is created, so fields that no result has,
+// e.g. a search with no matches, are shown empty. When none are selected the
+// status, name, fields and message columns are used.
+func selectColumns(results []Result, selected []string) []string {
+	if len(selected) > 0 {
+		return selected
+	}
+
+	columns := append([]string{StatusColumn, NameColumn}, fieldNames(results)...)
+	return append(columns, MessageColumn)
+}
+
+// ValidateColumns checks the columns are the built in columns or one of the
+// fields, so unknown columns are reported before a command does any work.
+func ValidateColumns(columns []string, fields []string) error {
+	names := append([]string{StatusColumn, NameColumn, MessageColumn}, fields...)
+	sort.Strings(names)
+
+	for _, name := range columns {
+		i := sort.SearchStrings(names, name)
+		if i == len(names) || names[i] != name {
+			return fmt.Errorf("unknown column '%s', expected one of: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	return nil
+}
+
+// fieldNames returns the names of the fields of all results, in the order first seen.
+func fieldNames(results []Result) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	for _, result := range results {
+		for _, f := range result.Fields {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				names = append(names, f.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// rows returns the plain text values of the columns of each result.
+func rows(results []Result, columns []string) [][]string {
+	var rows [][]string
+
+	for _, result := range results {
+		var cells []string
+
+		for _, name := range columns {
+			value, _ := result.Field(name)
+			cells = append(cells, value)
+		}
+
+		rows = append(rows, cells)
+	}
+
+	return rows
+}
+
+func columnFor(name string) Column {
+	switch name {
+	case NameColumn:
+		return Column{Header: "REPOSITORY NAME", MinWidth: 20}
+	case MessageColumn:
+		return Column{Header: "MESSAGE", MinWidth: 20}
+	}
+
+	return Column{Header: strings.ToUpper(strings.ReplaceAll(name, "-", " "))}
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableOutputterRendersOnClose(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := NewTableOutputter(&buf, DefaultLevel, nil)
+
+	// When
+	testee.Result(Result{Repository: "repo-a", Outcome: OK, Message: "Up to date",
+		Fields: []Field{{Name: "branch", Value: "main"}}})
+
+	// Then
+	assert.Equal(t, buf.String(), "")
+
+	assert.Nil(t, testee.Close())
+	assert.Contains(t, buf.String(), "BRANCH")
+	assert.Contains(t, buf.String(), "repo-a")
+}
+
+func TestTableOutputterSelectedFieldWithoutValues(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := NewTableOutputter(&buf, DefaultLevel, []string{"name", "path", "message"})
+
+	// When
+	testee.Result(Result{Repository: "plain", Outcome: Skipped, Message: "Not versioned"})
+
+	// Then
+	assert.Nil(t, testee.Close())
+	assert.Contains(t, buf.String(), "PATH")
+	assert.Contains(t, buf.String(), "Not versioned")
+}
+
+func TestValidateColumns(t *testing.T) {
+	// Given
+	fields := []string{"branch", "files"}
+
+	// When
+	err := ValidateColumns([]string{"name", "files", "message"}, fields)
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestValidateColumnsUnknown(t *testing.T) {
+	// Given
+	fields := []string{"branch", "files"}
+
+	// When
+	err := ValidateColumns([]string{"name", "typo"}, fields)
+
+	// Then
+	assert.EqualError(t, err, "unknown column 'typo', expected one of: branch, files, message, name, status")
+}
+
+func TestNewRejectsUnknownColumns(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+
+	// When
+	_, err := New(TableFormat, &buf, &buf, Options{Columns: []string{"typo"}, Fields: []string{"branch"}})
+
+	// Then
+	assert.EqualError(t, err, "unknown column 'typo', expected one of: branch, message, name, status")
+}
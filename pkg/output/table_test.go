@@ -0,0 +1,67 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	assert.Equal(t, 7, DisplayWidth("\x1b[36mSUCCESS\x1b[0m"))
+	assert.Equal(t, 6, DisplayWidth("界面ab"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "repos…", Truncate("repository", 6))
+	assert.Equal(t, "界…", Truncate("界面界面", 4))
+	assert.Equal(t, "\x1b[33mChan…\x1b[0m", Truncate("\x1b[33mChanges to pull\x1b[0m", 5))
+}
+
+func TestTableRender(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := Table{
+		Columns: []Column{{Header: "NAME", MinWidth: 4}, {Header: "BRANCH", MinWidth: 6}, {Header: "MESSAGE"}},
+		Width:   30,
+	}
+	testee.AddRow("kl-cli", "feature/long-branch-name", "\x1b[36mUp to date\x1b[0m")
+	testee.AddRow("界面", "main", "")
+
+	// When
+	err := testee.Render(&buf)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"NAME   BRANCH       MESSAGE\n"+
+		"kl-cli feature/lon… \x1b[36mUp to date\x1b[0m\n"+
+		"界面   main\n")
+}
+
+func TestTableRenderHeadings(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	testee := Table{
+		Columns: []Column{{Header: "NAME", MinWidth: 4}, {Header: "MESSAGE"}},
+	}
+	testee.AddHeading("github.com/klyall")
+	testee.AddRow("kl-cli", "ok")
+	testee.AddRow("other", "ok")
+	testee.AddHeading("Other")
+	testee.AddRow("plain", "Not versioned")
+
+	// When
+	err := testee.Render(&buf)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, buf.String(), ""+
+		"NAME   MESSAGE\n"+
+		"github.com/klyall\n"+
+		"kl-cli ok\n"+
+		"other  ok\n"+
+		"Other\n"+
+		"plain  Not versioned\n")
+}
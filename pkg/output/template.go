@@ -0,0 +1,170 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// TemplateOutputter writes each result with a Go template, e.g.
+//
+//	{{.Name}} {{.LocalBranch}} {{.CommitsBehind}}
+//
+// The template is given the repository Name, Operation, Outcome, Status and
+// Message of the result along with the fields of its data, e.g. the
+// git.RepositoryStatus of each repository for 'kl git status'. Log messages
+// and the summary are written to a separate writer so the report only holds
+// the templated lines.
+type TemplateOutputter struct {
+	SStdOut
+
+	out      io.Writer
+	template *template.Template
+	results  []Result
+	summary  *Summary
+}
+
+// TemplateFuncs are the helper functions available to templates along with
+// the text/template builtins.
+var TemplateFuncs = template.FuncMap{
+	"color":    colorFunc,
+	"error":    func(v interface{}) string { return ErrorColor.Render(v) },
+	"warn":     func(v interface{}) string { return WarnColor.Render(v) },
+	"success":  func(v interface{}) string { return SuccessColor.Render(v) },
+	"info":     func(v interface{}) string { return InfoColor.Render(v) },
+	"debug":    func(v interface{}) string { return DebugColor.Render(v) },
+	"outcome":  func(o Outcome, v interface{}) string { return o.Color().Render(v) },
+	"pad":      func(width int, v interface{}) string { return Pad(fmt.Sprint(v), width) },
+	"padLeft":  padLeft,
+	"truncate": func(width int, v interface{}) string { return Truncate(fmt.Sprint(v), width) },
+	"upper":    func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower":    func(v interface{}) string { return strings.ToLower(fmt.Sprint(v)) },
+	"join":     strings.Join,
+	"json":     jsonFunc,
+}
+
+func NewTemplateOutputter(out io.Writer, log io.Writer, level Level, format string) (*TemplateOutputter, error) {
+	// Allow \t and \n to be typed on the command line, as with docker --format
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+
+	t, err := template.New("format").Funcs(TemplateFuncs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+
+	return &TemplateOutputter{
+		SStdOut: SStdOut{
+			Out:   log,
+			Level: level,
+		},
+		out:      out,
+		template: t,
+	}, nil
+}
+
+func (t *TemplateOutputter) Result(result Result) {
+	if result.Problem() || t.Enabled(DefaultLevel) {
+		t.results = append(t.results, result)
+	}
+}
+
+// Summary is held back until the results have been written.
+func (t *TemplateOutputter) Summary(summary Summary) {
+	t.summary = &summary
+}
+
+// Close writes the results. Fields missing from some results, e.g. the status
+// of a directory that is not a git repository, are given zero values so the
+// template can be used for every result.
+func (t *TemplateOutputter) Close() error {
+	var values []map[string]interface{}
+	zeros := map[string]interface{}{}
+
+	for _, result := range t.results {
+		v := templateValues(result)
+		for key, value := range v {
+			if _, ok := zeros[key]; !ok && value != nil {
+				zeros[key] = reflect.Zero(reflect.TypeOf(value)).Interface()
+			}
+		}
+		values = append(values, v)
+	}
+
+	for _, v := range values {
+		for key, zero := range zeros {
+			if v[key] == nil {
+				v[key] = zero
+			}
+		}
+
+		if err := t.template.Execute(t.out, v); err != nil {
+			return err
+		}
+	}
+
+	if t.summary != nil {
+		t.SStdOut.Summary(*t.summary)
+	}
+
+	return nil
+}
+
+func templateValues(result Result) map[string]interface{} {
+	values := map[string]interface{}{}
+
+	data := reflect.Indirect(reflect.ValueOf(result.Data))
+
+	if data.Kind() == reflect.Struct {
+		for i := 0; i < data.NumField(); i++ {
+			if f := data.Type().Field(i); f.IsExported() {
+				values[f.Name] = data.Field(i).Interface()
+			}
+		}
+	}
+
+	for _, f := range result.Fields {
+		if _, ok := values[f.Name]; !ok {
+			values[f.Name] = f.Value
+		}
+	}
+
+	values["Data"] = result.Data
+	values["Name"] = result.Repository
+	values["Operation"] = result.Operation
+	values["Outcome"] = result.Outcome
+	values["Status"] = result.Outcome.Label()
+	values["Message"] = result.Message
+
+	return values
+}
+
+func colorFunc(name string, v interface{}) (string, error) {
+	c, err := parseColor(name)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Render(v), nil
+}
+
+func padLeft(width int, v interface{}) string {
+	s := fmt.Sprint(v)
+
+	if w := DisplayWidth(s); w < width {
+		return strings.Repeat(" ", width-w) + s
+	}
+
+	return s
+}
+
+func jsonFunc(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
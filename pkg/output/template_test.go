@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type templateTestData struct {
+	LocalBranch   string
+	CommitsBehind int
+}
+
+func TestTemplateOutputterExposesResultAndData(t *testing.T) {
+	// Given
+	var out, log bytes.Buffer
+	testee, err := NewTemplateOutputter(&out, &log, DefaultLevel, `{{pad 8 .Name}}{{.LocalBranch}}\t{{.CommitsBehind}}`)
+	assert.Nil(t, err)
+
+	// When
+	testee.Result(Result{Repository: "repo-a", Outcome: OK, Data: templateTestData{"main", 2}})
+	testee.Result(Result{Repository: "plain", Outcome: Skipped})
+	err = testee.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, out.String(), "repo-a  main\t2\nplain   \t0\n")
+}
+
+func TestTemplateOutputterRejectsInvalidTemplate(t *testing.T) {
+	// When
+	_, err := NewTemplateOutputter(&bytes.Buffer{}, &bytes.Buffer{}, DefaultLevel, "{{.Name")
+
+	// Then
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Snapshot is the commit each repository of a workspace had checked out, so
+// the workspace can be restored to it later.
+type Snapshot struct {
+	Created      time.Time    `json:"created"`
+	Repositories []Repository `json:"repositories"`
+}
+
+type Repository struct {
+	Name string `json:"name"`
+	// Branch checked out, empty when HEAD was detached
+	Branch string `json:"branch,omitempty"`
+	SHA    string `json:"sha"`
+	// Dirty is set when there were uncommitted changes, which are not saved
+	Dirty  bool   `json:"dirty"`
+	Remote string `json:"remote,omitempty"`
+}
+
+// Commits are 40 hex digits, or 64 in a repository using SHA-256
+var commitSHA = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// Validate checks a repository read from a snapshot, which may have been shared,
+// can only refer to a sub-directory of the workspace and to a single commit.
+func (r Repository) Validate() error {
+	if r.Name == "" || r.Name == "." || r.Name == ".." || filepath.IsAbs(r.Name) || filepath.Base(r.Name) != r.Name {
+		return fmt.Errorf("invalid name '%s', expected the name of a sub-directory", r.Name)
+	}
+
+	if !commitSHA.MatchString(r.SHA) {
+		return fmt.Errorf("invalid sha '%s', expected a full commit id", r.SHA)
+	}
+
+	return nil
+}
+
+// Load reads a snapshot saved as JSON.
+func Load(file string) (Snapshot, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(content, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("invalid snapshot '%s': %w", file, err)
+	}
+
+	return s, nil
+}
+
+// Save writes the snapshot as JSON.
+func (s Snapshot) Save(file string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, append(content, '\n'), 0644)
+}
+
+// ChangeKind is how a repository differs between two snapshots.
+type ChangeKind string
+
+const (
+	Added     ChangeKind = "added"
+	Removed   ChangeKind = "removed"
+	Moved     ChangeKind = "moved"
+	Unchanged ChangeKind = "unchanged"
+)
+
+// Change is a repository of either snapshot, with From or To nil when it is
+// only in the other.
+type Change struct {
+	Name string      `json:"name"`
+	Kind ChangeKind  `json:"kind"`
+	From *Repository `json:"from,omitempty"`
+	To   *Repository `json:"to,omitempty"`
+}
+
+// Compare returns how each repository changed between the snapshots, by name.
+// A repository has moved when its branch or commit differs.
+func Compare(from, to Snapshot) []Change {
+	changes := map[string]*Change{}
+
+	for i := range from.Repositories {
+		r := &from.Repositories[i]
+		changes[r.Name] = &Change{Name: r.Name, Kind: Removed, From: r}
+	}
+
+	for i := range to.Repositories {
+		r := &to.Repositories[i]
+
+		c, ok := changes[r.Name]
+		if !ok {
+			changes[r.Name] = &Change{Name: r.Name, Kind: Added, To: r}
+			continue
+		}
+
+		c.To = r
+
+		if c.From.SHA == r.SHA && c.From.Branch == r.Branch {
+			c.Kind = Unchanged
+		} else {
+			c.Kind = Moved
+		}
+	}
+
+	var names []string
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []Change
+	for _, name := range names {
+		result = append(result, *changes[name])
+	}
+
+	return result
+}
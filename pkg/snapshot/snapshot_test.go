@@ -0,0 +1,94 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	// Given
+	from := Snapshot{Repositories: []Repository{
+		{Name: "repo-a", Branch: "main", SHA: "aaa"},
+		{Name: "repo-b", Branch: "main", SHA: "bbb"},
+		{Name: "repo-c", Branch: "main", SHA: "ccc"},
+	}}
+	to := Snapshot{Repositories: []Repository{
+		{Name: "repo-a", Branch: "main", SHA: "aaa"},
+		{Name: "repo-b", Branch: "main", SHA: "bbc"},
+		{Name: "repo-d", Branch: "main", SHA: "ddd"},
+	}}
+
+	// When
+	changes := Compare(from, to)
+
+	// Then
+	var kinds []ChangeKind
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind)
+	}
+
+	assert.Equal(t, kinds, []ChangeKind{Unchanged, Moved, Removed, Added})
+	assert.Equal(t, changes[1].From.SHA, "bbb")
+	assert.Equal(t, changes[1].To.SHA, "bbc")
+	assert.Nil(t, changes[2].To)
+	assert.Nil(t, changes[3].From)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	// Given
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+	saved := Snapshot{
+		Created:      time.Date(2021, 11, 5, 10, 15, 0, 0, time.UTC),
+		Repositories: []Repository{{Name: "repo-a", Branch: "main", SHA: "aaa", Dirty: true}},
+	}
+
+	// When
+	assert.Nil(t, saved.Save(file))
+	loaded, err := Load(file)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, loaded, saved)
+}
+
+func TestRepositoryValidate(t *testing.T) {
+	sha := "2f1c5c0a7d9e4b3f8a6c1e0d5b7f9a2c4e6d8b0a"
+
+	tests := []struct {
+		name       string
+		repository Repository
+		err        string
+	}{
+		{"valid", Repository{Name: "repo-a", SHA: sha}, ""},
+		{"valid sha-256", Repository{Name: "repo-a", SHA: sha + sha[:24]}, ""},
+		{"long sha", Repository{Name: "repo-a", SHA: sha + sha[:8]},
+			"invalid sha '" + sha + sha[:8] + "', expected a full commit id"},
+		{"empty name", Repository{Name: "", SHA: sha}, "invalid name '', expected the name of a sub-directory"},
+		{"current directory", Repository{Name: ".", SHA: sha}, "invalid name '.', expected the name of a sub-directory"},
+		{"parent directory", Repository{Name: "..", SHA: sha}, "invalid name '..', expected the name of a sub-directory"},
+		{"outside workspace", Repository{Name: "../ws1/a", SHA: sha}, "invalid name '../ws1/a', expected the name of a sub-directory"},
+		{"nested", Repository{Name: "repo-a/sub", SHA: sha}, "invalid name 'repo-a/sub', expected the name of a sub-directory"},
+		{"absolute", Repository{Name: "/tmp/repo-a", SHA: sha}, "invalid name '/tmp/repo-a', expected the name of a sub-directory"},
+		{"rev", Repository{Name: "repo-a", SHA: "HEAD"}, "invalid sha 'HEAD', expected a full commit id"},
+		{"short sha", Repository{Name: "repo-a", SHA: sha[:7]}, "invalid sha '2f1c5c0', expected a full commit id"},
+		{"upper case sha", Repository{Name: "repo-a", SHA: "2F1C5C0A7D9E4B3F8A6C1E0D5B7F9A2C4E6D8B0A"},
+			"invalid sha '2F1C5C0A7D9E4B3F8A6C1E0D5B7F9A2C4E6D8B0A', expected a full commit id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			err := tt.repository.Validate()
+
+			// Then
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,734 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/mattn/go-runewidth"
+)
+
+// maxConcurrentActions limits the number of git processes run at once
+const maxConcurrentActions = 8
+
+const help = "↑/↓ move  enter details  f fetch  p pull  u push  x purge  r refresh  (shift for all)  q quit"
+
+var (
+	defaultStyle  = tcell.StyleDefault
+	barStyle      = tcell.StyleDefault.Reverse(true)
+	headerStyle   = tcell.StyleDefault.Bold(true)
+	selectedStyle = tcell.StyleDefault.Reverse(true)
+	errorStyle    = tcell.StyleDefault.Foreground(tcell.ColorRed)
+	infoStyle     = tcell.StyleDefault.Foreground(tcell.ColorTeal)
+	warnStyle     = tcell.StyleDefault.Foreground(tcell.ColorOlive)
+	debugStyle    = tcell.StyleDefault.Foreground(tcell.ColorGray)
+)
+
+type repository struct {
+	name    string
+	dir     string
+	status  git.RepositoryStatus
+	err     error
+	busy    string
+	message string
+	failed  bool
+	details *details
+	// loadingDetails is set while the details are read in the background
+	loadingDetails bool
+	// generation is incremented when an action changes the repository, so
+	// details read before it are not kept
+	generation int
+}
+
+type details struct {
+	branches []git.LocalBranch
+	remote   git.RepositoryRemote
+	err      error
+}
+
+// confirmation is an action waiting for the user to answer y or n.
+type confirmation struct {
+	prompt string
+	action func()
+}
+
+// Dashboard is a full screen view of the status of every repository in a
+// working directory, with actions to fetch, pull, push and purge them.
+type Dashboard struct {
+	Dir    string
+	Status func(repositoryDir string) (git.RepositoryStatus, error)
+
+	outputter    output.Outputter
+	actions      chan struct{}
+	screen       tcell.Screen
+	repositories []*repository
+	selected     int
+	offset       int
+	showDetails  bool
+	confirm      *confirmation
+}
+
+func (d *Dashboard) Run() error {
+	entries, err := os.ReadDir(d.Dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			d.repositories = append(d.repositories, &repository{
+				name: entry.Name(),
+				dir:  filepath.Join(d.Dir, entry.Name()),
+			})
+		}
+	}
+
+	sort.Slice(d.repositories, func(i, j int) bool {
+		return d.repositories[i].name < d.repositories[j].name
+	})
+
+	// Output from git would corrupt the screen, so it is discarded
+	d.outputter = output.SStdOut{Out: io.Discard}
+	d.actions = make(chan struct{}, maxConcurrentActions)
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	d.screen = screen
+
+	for _, r := range d.repositories {
+		d.refresh(r)
+	}
+
+	for {
+		d.draw()
+
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventInterrupt:
+			ev.Data().(func())()
+		case *tcell.EventKey:
+			if quit := d.handleKey(ev); quit {
+				return nil
+			}
+		}
+	}
+}
+
+func (d *Dashboard) handleKey(ev *tcell.EventKey) bool {
+	// Any key other than y cancels an action waiting for confirmation
+	if d.confirm != nil {
+		confirm := d.confirm
+		d.confirm = nil
+
+		if ev.Key() == tcell.KeyRune && unicode.ToLower(ev.Rune()) == 'y' {
+			confirm.action()
+		}
+
+		return false
+	}
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return true
+	case tcell.KeyEscape:
+		if !d.showDetails {
+			return true
+		}
+		d.showDetails = false
+	case tcell.KeyEnter, tcell.KeyTab:
+		d.showDetails = !d.showDetails
+	case tcell.KeyUp:
+		d.moveTo(d.selected - 1)
+	case tcell.KeyDown:
+		d.moveTo(d.selected + 1)
+	case tcell.KeyPgUp:
+		d.moveTo(d.selected - d.listHeight())
+	case tcell.KeyPgDn:
+		d.moveTo(d.selected + d.listHeight())
+	case tcell.KeyHome:
+		d.moveTo(0)
+	case tcell.KeyEnd:
+		d.moveTo(len(d.repositories) - 1)
+	case tcell.KeyRune:
+		return d.handleRune(ev.Rune())
+	}
+
+	return false
+}
+
+func (d *Dashboard) handleRune(r rune) bool {
+	actions := map[rune]func(*repository){
+		'f': d.fetch,
+		'p': d.pull,
+		'u': d.push,
+		'x': d.purge,
+		'r': d.refresh,
+	}
+
+	switch r {
+	case 'q':
+		return true
+	case 'k':
+		d.moveTo(d.selected - 1)
+	case 'j':
+		d.moveTo(d.selected + 1)
+	case 'g':
+		d.moveTo(0)
+	case 'G':
+		d.moveTo(len(d.repositories) - 1)
+	default:
+		action, ok := actions[unicode.ToLower(r)]
+
+		var repositories []*repository
+
+		switch {
+		case !ok:
+			return false
+		case unicode.IsUpper(r):
+			repositories = d.repositories
+		default:
+			if selected := d.selectedRepository(); selected != nil {
+				repositories = []*repository{selected}
+			}
+		}
+
+		runAll := func() {
+			for _, repository := range repositories {
+				action(repository)
+			}
+		}
+
+		// Purge force deletes branches, so is only done once confirmed
+		if unicode.ToLower(r) == 'x' && len(repositories) > 0 {
+			d.confirm = &confirmation{
+				prompt: purgePrompt(repositories, len(d.repositories)),
+				action: runAll,
+			}
+			return false
+		}
+
+		runAll()
+	}
+
+	return false
+}
+
+func (d *Dashboard) moveTo(i int) {
+	if i >= len(d.repositories) {
+		i = len(d.repositories) - 1
+	}
+
+	if i < 0 {
+		i = 0
+	}
+
+	d.selected = i
+}
+
+func (d *Dashboard) selectedRepository() *repository {
+	if d.selected >= len(d.repositories) {
+		return nil
+	}
+
+	return d.repositories[d.selected]
+}
+
+func (d *Dashboard) fetch(r *repository) {
+	gitFetch := git.Fetch{
+		Outputter: d.outputter,
+	}
+
+	d.runVersioned(r, "Fetch", func(git.RepositoryStatus) (string, error) {
+		return "Fetch complete", gitFetch.Exec(r.dir)
+	})
+}
+
+func (d *Dashboard) pull(r *repository) {
+	gitPull := git.Pull{
+		Outputter: d.outputter,
+	}
+
+	d.runVersioned(r, "Pull", func(status git.RepositoryStatus) (string, error) {
+		switch {
+		case status.LocalStatus == git.UncommittedChanges:
+			return "", fmt.Errorf("uncommitted changes prevent pull being done")
+		case status.RemoteStatus == git.NoChanges:
+			return "No changes to pull", nil
+		}
+
+		return "Pull complete", gitPull.Exec(r.dir)
+	})
+}
+
+func (d *Dashboard) push(r *repository) {
+	gitPush := git.Push{
+		Outputter: d.outputter,
+	}
+
+	d.runVersioned(r, "Push", func(status git.RepositoryStatus) (string, error) {
+		if status.CommitsAhead == 0 {
+			return "No changes to push", nil
+		}
+
+		return "Push complete", gitPush.Exec(r.dir)
+	})
+}
+
+func (d *Dashboard) purge(r *repository) {
+	gitPurge := git.Purge{
+		Outputter: d.outputter,
+	}
+
+	d.runVersioned(r, "Purge", func(git.RepositoryStatus) (string, error) {
+		purge, err := gitPurge.Exec(r.dir)
+		if err != nil {
+			return "", err
+		}
+
+		return purgeMessage(purge)
+	})
+}
+
+func purgePrompt(repositories []*repository, total int) string {
+	if len(repositories) == 1 {
+		return fmt.Sprintf("Force delete branches with a gone upstream in %s? (y/n)", repositories[0].name)
+	}
+	return fmt.Sprintf("Force delete branches with a gone upstream in all %d repositories? (y/n)", total)
+}
+
+// purgeMessage describes the branches purged. The current branch cannot be
+// deleted, so it is reported as kept rather than failed.
+func purgeMessage(purge git.RepositoryPurge) (string, error) {
+	if !purge.HasRemote {
+		return "No remote", nil
+	}
+
+	var deleted, current, failed []string
+	for _, pb := range purge.Branches {
+		switch pb.Outcome {
+		case git.BranchDeleted:
+			deleted = append(deleted, string(pb.LocalBranchName))
+		case git.BranchIsCurrent:
+			current = append(current, string(pb.LocalBranchName))
+		default:
+			failed = append(failed, string(pb.LocalBranchName))
+		}
+	}
+
+	var parts []string
+
+	if len(deleted) > 0 {
+		parts = append(parts, "Purged "+strings.Join(deleted, ", "))
+	}
+
+	if len(current) > 0 {
+		parts = append(parts, "Kept current branch "+strings.Join(current, ", "))
+	}
+
+	if len(failed) > 0 {
+		return "", fmt.Errorf("unable to delete %s", strings.Join(failed, ", "))
+	}
+
+	if len(parts) == 0 {
+		return "Nothing to purge", nil
+	}
+
+	return strings.Join(parts, "; "), nil
+}
+
+func (d *Dashboard) refresh(r *repository) {
+	d.run(r, "Refresh", func(git.RepositoryStatus) (string, error) {
+		return "", nil
+	})
+}
+
+func (d *Dashboard) runVersioned(r *repository, operation string, action func(git.RepositoryStatus) (string, error)) {
+	// Whether the repository is versioned is not known until its status has been read
+	if r.busy != "" {
+		return
+	}
+
+	if r.err != nil {
+		r.message = fmt.Sprintf("%s failed: unable to read repository: %s", operation, r.err.Error())
+		r.failed = true
+		return
+	}
+
+	if !r.status.Versioned {
+		r.message = "Not versioned"
+		r.failed = true
+		return
+	}
+
+	d.run(r, operation, action)
+}
+
+// run performs the action in the background and then refreshes the status of
+// the repository. Only one action runs against a repository at a time.
+func (d *Dashboard) run(r *repository, operation string, action func(git.RepositoryStatus) (string, error)) {
+	if r.busy != "" {
+		return
+	}
+
+	r.busy = operation
+	status := r.status
+
+	go func() {
+		d.actions <- struct{}{}
+		message, err := action(status)
+		status, statusErr := d.Status(r.dir)
+		<-d.actions
+
+		d.screen.PostEvent(tcell.NewEventInterrupt(func() {
+			r.busy = ""
+			r.status = status
+			r.err = statusErr
+			r.details = nil
+			r.generation++
+			r.failed = err != nil
+
+			if err != nil {
+				r.message = fmt.Sprintf("%s failed: %s", operation, err.Error())
+			} else {
+				r.message = message
+			}
+		}))
+	}()
+}
+
+func (d *Dashboard) listHeight() int {
+	_, height := d.screen.Size()
+
+	// Title, header, message and help lines
+	height -= 4
+
+	if d.showDetails {
+		height /= 2
+	}
+
+	if height < 1 {
+		return 1
+	}
+
+	return height
+}
+
+func (d *Dashboard) draw() {
+	d.screen.Clear()
+
+	width, height := d.screen.Size()
+
+	d.drawBar(0, fmt.Sprintf(" kl ui  %s", d.Dir))
+
+	nameWidth := 15
+	for _, r := range d.repositories {
+		if w := runewidth.StringWidth(r.name); w > nameWidth {
+			nameWidth = w
+		}
+	}
+	if nameWidth > 40 {
+		nameWidth = 40
+	}
+
+	columns := []int{nameWidth, 30, 6, 6, 24}
+
+	headerStyles := []tcell.Style{headerStyle, headerStyle, headerStyle, headerStyle, headerStyle, headerStyle}
+	d.drawRow(1, columns, headerStyles, "REPOSITORY NAME", "BRANCH", "AHEAD", "BEHIND", "STATUS", "MESSAGE")
+
+	listHeight := d.listHeight()
+
+	if d.selected < d.offset {
+		d.offset = d.selected
+	}
+	if d.selected >= d.offset+listHeight {
+		d.offset = d.selected - listHeight + 1
+	}
+
+	for i := 0; i < listHeight && d.offset+i < len(d.repositories); i++ {
+		index := d.offset + i
+		d.drawRepository(2+i, columns, d.repositories[index], index == d.selected)
+	}
+
+	if d.showDetails {
+		if selected := d.selectedRepository(); selected != nil {
+			d.drawDetails(2+listHeight, height-2, selected)
+		}
+	}
+
+	if d.confirm != nil {
+		d.drawText(0, height-2, width, warnStyle, d.confirm.prompt)
+	} else if selected := d.selectedRepository(); selected != nil && selected.message != "" {
+		style := infoStyle
+		if selected.failed {
+			style = errorStyle
+		}
+		d.drawText(0, height-2, width, style, fmt.Sprintf("%s: %s", selected.name, selected.message))
+	}
+
+	d.drawBar(height-1, " "+help)
+
+	d.screen.Show()
+}
+
+func (d *Dashboard) drawBar(y int, text string) {
+	width, _ := d.screen.Size()
+
+	for x := 0; x < width; x++ {
+		d.screen.SetContent(x, y, ' ', nil, barStyle)
+	}
+
+	d.drawText(0, y, width, barStyle, text)
+}
+
+func (d *Dashboard) drawRepository(y int, columns []int, r *repository, selected bool) {
+	status := r.status
+
+	message := r.message
+	if r.busy != "" {
+		message = r.busy + "..."
+	}
+
+	var statusText string
+	statusStyle := statusMessageStyle(status.LocalStatus)
+
+	switch {
+	case r.err != nil:
+		statusText = "Unable to read repository"
+		statusStyle = errorStyle
+	case status.LocalStatus.Message == "":
+		// Status has not been read yet
+	case !status.Versioned:
+		statusText = git.NotVersioned.Message
+		statusStyle = errorStyle
+	case status.LocalStatus != git.NoChanges:
+		statusText = status.LocalStatus.Message
+	case status.RemoteStatus != git.NoChanges:
+		statusText = status.RemoteStatus.Message
+		statusStyle = statusMessageStyle(status.RemoteStatus)
+	default:
+		statusText = status.LocalStatus.Message
+	}
+
+	var ahead, behind string
+	if status.CommitsAhead > 0 {
+		ahead = fmt.Sprintf("↑%d", status.CommitsAhead)
+	}
+	if status.CommitsBehind > 0 {
+		behind = fmt.Sprintf("↓%d", status.CommitsBehind)
+	}
+
+	messageStyle := debugStyle
+	if r.failed {
+		messageStyle = errorStyle
+	}
+
+	styles := []tcell.Style{defaultStyle, defaultStyle, warnStyle, warnStyle, statusStyle, messageStyle}
+
+	if selected {
+		width, _ := d.screen.Size()
+		for x := 0; x < width; x++ {
+			d.screen.SetContent(x, y, ' ', nil, selectedStyle)
+		}
+
+		for i := range styles {
+			styles[i] = selectedStyle
+		}
+	}
+
+	d.drawRow(y, columns, styles, r.name, status.LocalBranch, ahead, behind, statusText, message)
+}
+
+func (d *Dashboard) drawRow(y int, columns []int, styles []tcell.Style, cells ...string) {
+	x := 0
+	for i, text := range cells {
+		width := -1
+		if i < len(columns) {
+			width = columns[i]
+		}
+		x = d.drawCell(x, y, width, styles[i], text)
+	}
+}
+
+// drawCell draws text truncated to width followed by a column separator,
+// returning the position of the next column. A negative width fills the rest
+// of the line.
+func (d *Dashboard) drawCell(x, y, width int, style tcell.Style, text string) int {
+	if width < 0 {
+		screenWidth, _ := d.screen.Size()
+		d.drawText(x, y, screenWidth-x, style, text)
+		return screenWidth
+	}
+
+	d.drawText(x, y, width, style, runewidth.Truncate(text, width, "…"))
+
+	return x + width + 1
+}
+
+func (d *Dashboard) drawText(x, y, width int, style tcell.Style, text string) {
+	end := x + width
+
+	for _, r := range text {
+		w := runewidth.RuneWidth(r)
+		if x+w > end {
+			return
+		}
+
+		d.screen.SetContent(x, y, r, nil, style)
+		x += w
+	}
+}
+
+func (d *Dashboard) drawDetails(top, bottom int, r *repository) {
+	width, _ := d.screen.Size()
+
+	if r.details == nil && r.status.Versioned {
+		d.loadDetailsInBackground(r)
+	}
+
+	var lines []string
+	var styles []tcell.Style
+
+	add := func(style tcell.Style, format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
+		styles = append(styles, style)
+	}
+
+	switch {
+	case !r.status.Versioned:
+		add(errorStyle, "Directory is not versioned")
+	case r.details == nil:
+		add(debugStyle, "Loading...")
+	case r.details.err != nil:
+		add(errorStyle, "Unable to read repository: %s", r.details.err.Error())
+	default:
+		remote := r.details.remote
+		add(headerStyle, "Remote")
+		switch {
+		case remote.Fetch == "":
+			add(infoStyle, "  No remote")
+		case remote.FetchURL.BrowserURL() != "":
+			add(defaultStyle, "  %s  %s", remote.Fetch, remote.FetchURL.BrowserURL())
+		default:
+			add(defaultStyle, "  %s", remote.Fetch)
+		}
+
+		add(headerStyle, "Branches")
+		for _, b := range r.details.branches {
+			marker := " "
+			style := defaultStyle
+			if b.CurrentBranch {
+				marker = "*"
+				style = infoStyle
+			}
+			add(style, "  %s %-30s %s", marker, b.LocalBranchName, b.RemoteBranchName)
+		}
+
+		add(headerStyle, "Files")
+		if len(r.status.FilesStatus) == 0 {
+			add(infoStyle, "  No changes")
+		}
+		for _, fs := range r.status.FilesStatus {
+			style := warnStyle
+			if fs.Staged {
+				style = infoStyle
+			}
+			add(style, "  %s", fs.Text)
+		}
+	}
+
+	for x := 0; x < width; x++ {
+		d.screen.SetContent(x, top, '─', nil, debugStyle)
+	}
+	d.drawText(1, top, width-1, headerStyle, " "+r.name+" ")
+
+	for i, line := range lines {
+		y := top + 1 + i
+		if y >= bottom {
+			break
+		}
+		d.drawText(0, y, width, styles[i], line)
+	}
+}
+
+// loadDetailsInBackground reads the details of the repository without
+// blocking the screen, which is redrawn once they have been read.
+func (d *Dashboard) loadDetailsInBackground(r *repository) {
+	if r.loadingDetails {
+		return
+	}
+
+	r.loadingDetails = true
+	generation := r.generation
+
+	go func() {
+		loaded := d.loadDetails(r)
+
+		d.screen.PostEvent(tcell.NewEventInterrupt(func() {
+			r.setDetails(generation, loaded)
+		}))
+	}()
+}
+
+// setDetails keeps the details read in the background, unless an action has
+// changed the repository since they started to be read.
+func (r *repository) setDetails(generation int, loaded *details) {
+	r.loadingDetails = false
+
+	if generation == r.generation {
+		r.details = loaded
+	}
+}
+
+func (d *Dashboard) loadDetails(r *repository) *details {
+	gitBranch := git.Branch{
+		Outputter: d.outputter,
+	}
+
+	gitRemote := git.Remote{
+		Outputter: d.outputter,
+	}
+
+	branches, err := gitBranch.ExecLocal(r.dir)
+	if err != nil {
+		return &details{err: err}
+	}
+
+	remote, err := gitRemote.Exec(r.dir)
+	if err != nil {
+		return &details{err: err}
+	}
+
+	return &details{
+		branches: branches,
+		remote:   remote,
+	}
+}
+
+func statusMessageStyle(statusMessage git.StatusMessage) tcell.Style {
+	switch statusMessage.Color {
+	case &output.ErrorColor:
+		return errorStyle
+	case &output.WarnColor:
+		return warnStyle
+	case &output.SuccessColor:
+		return infoStyle
+	}
+
+	return defaultStyle
+}
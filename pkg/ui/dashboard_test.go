@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		purge    git.RepositoryPurge
+		expected string
+		err      bool
+	}{
+		{"no remote", git.RepositoryPurge{}, "No remote", false},
+		{"nothing to purge", git.RepositoryPurge{HasRemote: true}, "Nothing to purge", false},
+		{"current branch kept", git.RepositoryPurge{HasRemote: true, Branches: []git.PurgedBranch{
+			{LocalBranchName: "feature/a", Outcome: git.BranchDeleted},
+			{LocalBranchName: "feature/b", Outcome: git.BranchIsCurrent},
+		}}, "Purged feature/a; Kept current branch feature/b", false},
+		{"delete failed", git.RepositoryPurge{HasRemote: true, Branches: []git.PurgedBranch{
+			{LocalBranchName: "feature/a", Outcome: git.BranchDeleteFailed, Err: errors.New("failed")},
+		}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			message, err := purgeMessage(tt.purge)
+
+			// Then
+			assert.Equal(t, message, tt.expected)
+			assert.Equal(t, err != nil, tt.err)
+		})
+	}
+}
+
+func TestPurgeWaitsForConfirmation(t *testing.T) {
+	// Given
+	r := &repository{name: "plain", status: git.RepositoryStatus{Versioned: false}}
+	testee := &Dashboard{repositories: []*repository{r}}
+
+	// When
+	testee.handleRune('x')
+
+	// Then
+	assert.NotNil(t, testee.confirm)
+	assert.Equal(t, r.message, "")
+
+	// When
+	testee.handleKey(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone))
+
+	// Then
+	assert.Nil(t, testee.confirm)
+	assert.Equal(t, r.message, "")
+
+	// When
+	testee.handleRune('x')
+	testee.handleKey(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone))
+
+	// Then
+	assert.Nil(t, testee.confirm)
+	assert.Equal(t, r.message, "Not versioned")
+}
+
+func TestRunVersionedWaitsForStatus(t *testing.T) {
+	// Given
+	r := &repository{name: "repo-a", busy: "Refresh"}
+	testee := &Dashboard{repositories: []*repository{r}}
+
+	// When
+	testee.handleRune('f')
+
+	// Then
+	assert.Equal(t, r.message, "")
+	assert.False(t, r.failed)
+}
+
+func TestSetDetails(t *testing.T) {
+	// Given
+	r := &repository{name: "repo-a", loadingDetails: true}
+	loaded := &details{remote: git.RepositoryRemote{Fetch: "origin"}}
+
+	// When
+	r.setDetails(0, loaded)
+
+	// Then
+	assert.False(t, r.loadingDetails)
+	assert.Equal(t, r.details, loaded)
+}
+
+func TestSetDetailsReadBeforeAction(t *testing.T) {
+	// Given
+	r := &repository{name: "repo-a", loadingDetails: true}
+	generation := r.generation
+
+	// An action finished while the details were being read
+	r.generation++
+
+	// When
+	r.setDetails(generation, &details{})
+
+	// Then
+	assert.False(t, r.loadingDetails)
+	assert.Nil(t, r.details)
+}
@@ -0,0 +1,208 @@
+package watch
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/klyall/kl-cli/pkg/git"
+	"github.com/klyall/kl-cli/pkg/output"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DefaultDebounce = 300 * time.Millisecond
+
+// Watcher reports which sub-directories of a working directory have changed,
+// either in their working tree or in their .git directory.
+type Watcher struct {
+	Outputter output.Outputter
+	Debounce  time.Duration
+
+	dir          string
+	fsWatcher    *fsnotify.Watcher
+	repositories map[string]map[string]bool
+}
+
+// NewWatcher watches dir for sub-directories being created or removed.
+// Repositories within it are watched once added with AddRepository.
+func NewWatcher(dir string, outputter output.Outputter) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fsWatcher.Add(dir); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	return &Watcher{
+		Outputter:    outputter,
+		Debounce:     DefaultDebounce,
+		dir:          dir,
+		fsWatcher:    fsWatcher,
+		repositories: map[string]map[string]bool{},
+	}, nil
+}
+
+func (w *Watcher) Close() error {
+	return w.fsWatcher.Close()
+}
+
+// AddRepository watches every directory of the repository's working tree that
+// is not ignored by git, along with the .git directory and its refs.
+func (w *Watcher) AddRepository(repositoryName string) error {
+	if _, ok := w.repositories[repositoryName]; ok {
+		return nil
+	}
+
+	repositoryDir := filepath.Join(w.dir, repositoryName)
+
+	lsFiles := git.LsFiles{
+		Outputter: w.Outputter,
+	}
+
+	ignored := map[string]bool{}
+
+	// Without the ignored directories everything is watched, which is slower but still correct
+	ignoredDirectories, _ := lsFiles.ExecIgnoredDirectories(repositoryDir)
+	for _, d := range ignoredDirectories {
+		ignored[filepath.Join(repositoryDir, d)] = true
+	}
+
+	w.repositories[repositoryName] = ignored
+
+	return w.addTree(repositoryDir, ignored)
+}
+
+// RemoveRepository forgets a repository that has been deleted, so it is
+// watched again by AddRepository if it is recreated.
+func (w *Watcher) RemoveRepository(repositoryName string) {
+	delete(w.repositories, repositoryName)
+}
+
+func (w *Watcher) addTree(root string, ignored map[string]bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories removed or unreadable while walking are skipped
+			return nil
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if ignored[path] {
+			return filepath.SkipDir
+		}
+
+		if d.Name() == ".git" {
+			if err := w.add(path); err != nil {
+				return err
+			}
+
+			if err := w.addTree(filepath.Join(path, "refs"), nil); err != nil {
+				return err
+			}
+
+			return filepath.SkipDir
+		}
+
+		return w.add(path)
+	})
+}
+
+func (w *Watcher) add(path string) error {
+	w.Outputter.Debug("Watching " + path)
+
+	return w.fsWatcher.Add(path)
+}
+
+// Run blocks, calling onChange with the names of the sub-directories that have
+// changed once no further changes have been seen for the debounce period.
+func (w *Watcher) Run(onChange func(repositoryNames []string)) error {
+	pending := map[string]bool{}
+
+	timer := time.NewTimer(w.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+
+			repositoryName, ok := w.repositoryName(event.Name)
+			if !ok {
+				continue
+			}
+
+			w.Outputter.Debug(event)
+
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				w.addCreated(repositoryName, event.Name)
+			}
+
+			pending[repositoryName] = true
+			timer.Reset(w.Debounce)
+
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			w.Outputter.Error(err)
+
+		case <-timer.C:
+			var names []string
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			pending = map[string]bool{}
+
+			onChange(names)
+		}
+	}
+}
+
+// repositoryName returns the sub-directory of the working directory that path
+// belongs to.
+func (w *Watcher) repositoryName(path string) (string, bool) {
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+
+	return strings.SplitN(rel, string(filepath.Separator), 2)[0], true
+}
+
+// addCreated starts watching directories created within a watched working tree.
+func (w *Watcher) addCreated(repositoryName, path string) {
+	ignored, ok := w.repositories[repositoryName]
+	if !ok {
+		return
+	}
+
+	sep := string(filepath.Separator)
+
+	switch {
+	case strings.Contains(path, sep+".git"+sep+"refs"+sep):
+		ignored = nil
+	case strings.Contains(path, sep+".git"+sep):
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return
+	}
+
+	if err := w.addTree(path, ignored); err != nil {
+		w.Outputter.Error(err)
+	}
+}
@@ -0,0 +1,120 @@
+package watch
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klyall/kl-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWatcher(t *testing.T) (*Watcher, string) {
+	dir := t.TempDir()
+
+	w, err := NewWatcher(dir, output.SStdOut{Out: io.Discard})
+	assert.Nil(t, err)
+	t.Cleanup(func() { w.Close() })
+
+	w.Debounce = 100 * time.Millisecond
+
+	return w, dir
+}
+
+// runWatcher runs the watcher until the test ends, sending each change to the
+// returned channel.
+func runWatcher(w *Watcher) <-chan []string {
+	changes := make(chan []string, 10)
+
+	go func() {
+		_ = w.Run(func(repositoryNames []string) {
+			changes <- repositoryNames
+		})
+	}()
+
+	return changes
+}
+
+func TestRunDebouncesChanges(t *testing.T) {
+	// Given
+	w, dir := newTestWatcher(t)
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "repo-a"), 0755))
+	assert.Nil(t, w.AddRepository("repo-a"))
+	changes := runWatcher(w)
+
+	// When
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "repo-a", "file.txt"), []byte{byte(i)}, 0644))
+	}
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "repo-b"), 0755))
+
+	// Then
+	select {
+	case names := <-changes:
+		assert.Equal(t, names, []string{"repo-a", "repo-b"})
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+
+	select {
+	case names := <-changes:
+		t.Fatalf("changes reported again: %v", names)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestAddRepositoryWatchesNewDirectories(t *testing.T) {
+	// Given
+	w, dir := newTestWatcher(t)
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "repo-a"), 0755))
+	assert.Nil(t, w.AddRepository("repo-a"))
+	changes := runWatcher(w)
+
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "repo-a", "src"), 0755))
+	<-changes
+
+	// When
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "repo-a", "src", "main.go"), []byte("package main"), 0644))
+
+	// Then
+	select {
+	case names := <-changes:
+		assert.Equal(t, names, []string{"repo-a"})
+	case <-time.After(5 * time.Second):
+		t.Fatal("change in a new directory not reported")
+	}
+}
+
+func TestRemoveRepository(t *testing.T) {
+	// Given
+	w, dir := newTestWatcher(t)
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "repo-a"), 0755))
+	assert.Nil(t, w.AddRepository("repo-a"))
+
+	// When
+	w.RemoveRepository("repo-a")
+
+	// Then
+	_, ok := w.repositories["repo-a"]
+	assert.False(t, ok)
+
+	assert.Nil(t, w.AddRepository("repo-a"))
+	_, ok = w.repositories["repo-a"]
+	assert.True(t, ok)
+}
+
+func TestRepositoryName(t *testing.T) {
+	// Given
+	w, dir := newTestWatcher(t)
+
+	// When
+	name, ok := w.repositoryName(filepath.Join(dir, "repo-a", ".git", "HEAD"))
+	_, outside := w.repositoryName(filepath.Dir(dir))
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, name, "repo-a")
+	assert.False(t, outside)
+}